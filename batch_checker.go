@@ -0,0 +1,39 @@
+package bloomd
+
+import (
+	"time"
+)
+
+// BatchChecker merges Check calls from many goroutines into a single multi check command per filter
+// a batch is sent when it reaches maxBatchSize keys or maxDelay after its first key was added
+type BatchChecker struct {
+	batcher *batcher
+}
+
+// NewBatchChecker creates a batch checker sending batches through clients of the pool
+func NewBatchChecker(pool *Pool, maxBatchSize int, maxDelay time.Duration) *BatchChecker {
+	return &BatchChecker{
+		batcher: newBatcher(pool, "m", maxBatchSize, maxDelay),
+	}
+}
+
+// Check checks key in the filter and waits until its batch is sent, the result is the same as of Filter.Check
+func (c *BatchChecker) Check(filterName string, key Key) (bool, error) {
+	waiter := make(chan batchResult, 1)
+	if err := c.batcher.add(filterName, key, waiter); err != nil {
+		return false, err
+	}
+	res := <-waiter
+	return res.found, res.err
+}
+
+// Flush sends all pending batches and waits until their results are delivered
+func (c *BatchChecker) Flush() {
+	c.batcher.flush()
+}
+
+// Close flushes pending batches, keys can not be checked after the checker is closed
+func (c *BatchChecker) Close() error {
+	c.batcher.close()
+	return nil
+}
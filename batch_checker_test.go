@@ -0,0 +1,75 @@
+package bloomd
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Applifier/go-bloomd/utils/testutils"
+)
+
+func TestBatchChecker(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		pool, err := NewPoolFromURL(1, 5, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		f, err := c.CreateFilter("batch_checker_"+url.Scheme, 0, 0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Drop()
+
+		t.Run("concurrent checks should receive own results", func(t *testing.T) {
+			for i := 0; i < 25; i += 2 {
+				if _, err := f.Set(Key(fmt.Sprintf("key_%d", i))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			checker := NewBatchChecker(pool, 10, 10*time.Millisecond)
+			defer checker.Close()
+
+			var wg sync.WaitGroup
+			results := make([]bool, 25)
+			errs := make([]error, 25)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], errs[i] = checker.Check(f.Name, Key(fmt.Sprintf("key_%d", i)))
+				}(i)
+			}
+			wg.Wait()
+			for i, res := range results {
+				if errs[i] != nil {
+					t.Fatal(errs[i])
+				}
+				if res != (i%2 == 0) {
+					t.Errorf("wrong result for key_%d", i)
+				}
+			}
+		})
+
+		t.Run("checks of missing filter should fail", func(t *testing.T) {
+			checker := NewBatchChecker(pool, 10, time.Millisecond)
+			defer checker.Close()
+			if _, err := checker.Check("batch_checker_missing_"+url.Scheme, Key("foo")); err == nil {
+				t.Error("check should fail")
+			}
+		})
+
+		t.Run("closed checker should reject keys", func(t *testing.T) {
+			checker := NewBatchChecker(pool, 10, time.Millisecond)
+			checker.Close()
+			if _, err := checker.Check(f.Name, Key("foo")); err != ErrBatcherClosed {
+				t.Errorf("closed checker should reject keys but returned %v", err)
+			}
+		})
+	})
+}
@@ -0,0 +1,50 @@
+package bloomd
+
+import (
+	"time"
+)
+
+// BatchWriter groups Set calls from many goroutines into a single bulk command per filter
+// a batch is sent when it reaches maxBatchSize keys or maxDelay after its first key was added
+type BatchWriter struct {
+	batcher *batcher
+}
+
+// NewBatchWriter creates a batch writer sending batches through clients of the pool
+func NewBatchWriter(pool *Pool, maxBatchSize int, maxDelay time.Duration) *BatchWriter {
+	return &BatchWriter{
+		batcher: newBatcher(pool, "b", maxBatchSize, maxDelay),
+	}
+}
+
+// OnError sets a callback for errors of keys added by SetAsync
+// it should be called before the writer is used
+func (w *BatchWriter) OnError(fn func(filterName string, keys []Key, err error)) {
+	w.batcher.onError = fn
+}
+
+// Set adds key to the filter and waits until its batch is sent, the result is the same as of Filter.Set
+func (w *BatchWriter) Set(filterName string, key Key) (bool, error) {
+	waiter := make(chan batchResult, 1)
+	if err := w.batcher.add(filterName, key, waiter); err != nil {
+		return false, err
+	}
+	res := <-waiter
+	return res.found, res.err
+}
+
+// SetAsync adds key to the filter without waiting for the result, errors are reported to the OnError callback
+func (w *BatchWriter) SetAsync(filterName string, key Key) error {
+	return w.batcher.add(filterName, key, nil)
+}
+
+// Flush sends all pending batches and waits until they are written
+func (w *BatchWriter) Flush() {
+	w.batcher.flush()
+}
+
+// Close flushes pending batches, keys can not be added after the writer is closed
+func (w *BatchWriter) Close() error {
+	w.batcher.close()
+	return nil
+}
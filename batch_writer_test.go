@@ -0,0 +1,99 @@
+package bloomd
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Applifier/go-bloomd/utils/testutils"
+)
+
+func TestBatchWriter(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		pool, err := NewPoolFromURL(1, 5, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		f, err := c.CreateFilter("batch_writer_"+url.Scheme, 0, 0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Drop()
+
+		t.Run("concurrent sets should receive own results", func(t *testing.T) {
+			w := NewBatchWriter(pool, 10, 10*time.Millisecond)
+			defer w.Close()
+			if _, err := f.Set(Key("key_0")); err != nil {
+				t.Fatal(err)
+			}
+
+			var wg sync.WaitGroup
+			results := make([]bool, 25)
+			errs := make([]error, 25)
+			for i := range results {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					results[i], errs[i] = w.Set(f.Name, Key(fmt.Sprintf("key_%d", i)))
+				}(i)
+			}
+			wg.Wait()
+			for i, res := range results {
+				if errs[i] != nil {
+					t.Fatal(errs[i])
+				}
+				if res != (i != 0) {
+					t.Errorf("wrong result for key_%d", i)
+				}
+			}
+		})
+
+		t.Run("close should flush async sets", func(t *testing.T) {
+			w := NewBatchWriter(pool, 100, time.Hour)
+			for i := 0; i < 5; i++ {
+				if err := w.SetAsync(f.Name, Key(fmt.Sprintf("async_%d", i))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.SetAsync(f.Name, Key("async_5")); err != ErrBatcherClosed {
+				t.Errorf("closed writer should reject keys but returned %v", err)
+			}
+			found, err := f.Check(Key("async_4"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Error("key should be set on close")
+			}
+		})
+
+		t.Run("errors of async sets should be reported to callback", func(t *testing.T) {
+			w := NewBatchWriter(pool, 100, time.Millisecond)
+			var lock sync.Mutex
+			var failed []Key
+			w.OnError(func(filterName string, keys []Key, err error) {
+				lock.Lock()
+				defer lock.Unlock()
+				failed = append(failed, keys...)
+			})
+			w.SetAsync("batch_writer_missing_"+url.Scheme, Key("foo"))
+			w.SetAsync("batch_writer_missing_"+url.Scheme, Key("bar"))
+			w.Close()
+
+			lock.Lock()
+			defer lock.Unlock()
+			if len(failed) != 2 {
+				t.Errorf("2 keys should fail but %d did", len(failed))
+			}
+		})
+	})
+}
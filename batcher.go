@@ -0,0 +1,187 @@
+package bloomd
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBatcherClosed is returned when a key is added after the batching writer or reader was closed
+var ErrBatcherClosed = errors.New("bloomd: batcher is closed")
+
+// batchResult is a result of a single key sent as a part of a batch
+type batchResult struct {
+	found bool
+	err   error
+}
+
+// batch collects keys of a single filter until it is sent
+type batch struct {
+	keys []Key
+	// waiters receive results of the keys, nil waiter means that nobody waits for the result
+	waiters []chan batchResult
+	timer   *time.Timer
+}
+
+// batcher groups keys per filter and sends each group as a single batch command
+// a group is sent when it reaches maxSize keys or maxDelay after its first key was added
+type batcher struct {
+	pool     *Pool
+	op       string
+	maxSize  int
+	maxDelay time.Duration
+	onError  func(filterName string, keys []Key, err error)
+
+	lock    sync.Mutex
+	batches map[string]*batch
+	closed  bool
+	// inflight is amount of batches being sent, idle is signaled when it drops to zero
+	inflight int
+	idle     *sync.Cond
+}
+
+func newBatcher(pool *Pool, op string, maxSize int, maxDelay time.Duration) *batcher {
+	b := &batcher{
+		pool:     pool,
+		op:       op,
+		maxSize:  maxSize,
+		maxDelay: maxDelay,
+		batches:  make(map[string]*batch),
+	}
+	b.idle = sync.NewCond(&b.lock)
+	return b
+}
+
+// add appends key to the batch of the filter, waiter receives the result if not nil
+func (b *batcher) add(filterName string, key Key, waiter chan batchResult) error {
+	b.lock.Lock()
+	if b.closed {
+		b.lock.Unlock()
+		return ErrBatcherClosed
+	}
+	bt, ok := b.batches[filterName]
+	if !ok {
+		bt = &batch{}
+		b.batches[filterName] = bt
+		bt.timer = time.AfterFunc(b.maxDelay, func() {
+			b.flushBatch(filterName, bt)
+		})
+	}
+	bt.keys = append(bt.keys, append(Key{}, key...))
+	bt.waiters = append(bt.waiters, waiter)
+	full := len(bt.keys) >= b.maxSize
+	if full {
+		delete(b.batches, filterName)
+		bt.timer.Stop()
+		b.inflight++
+	}
+	b.lock.Unlock()
+
+	if full {
+		go b.sendInflight(filterName, bt)
+	}
+	return nil
+}
+
+// flushBatch sends the batch if it was not sent yet
+func (b *batcher) flushBatch(filterName string, bt *batch) {
+	b.lock.Lock()
+	if b.batches[filterName] != bt {
+		b.lock.Unlock()
+		return
+	}
+	delete(b.batches, filterName)
+	b.inflight++
+	b.lock.Unlock()
+
+	b.sendInflight(filterName, bt)
+}
+
+// flush sends all pending batches and waits until they and batches sent concurrently are delivered
+func (b *batcher) flush() {
+	b.lock.Lock()
+	batches := b.batches
+	b.batches = make(map[string]*batch)
+	b.inflight += len(batches)
+	b.lock.Unlock()
+
+	for filterName, bt := range batches {
+		bt.timer.Stop()
+		go b.sendInflight(filterName, bt)
+	}
+
+	b.lock.Lock()
+	for b.inflight > 0 {
+		b.idle.Wait()
+	}
+	b.lock.Unlock()
+}
+
+// sendInflight sends the batch counted as in flight and signals when no batches are in flight
+func (b *batcher) sendInflight(filterName string, bt *batch) {
+	b.send(filterName, bt)
+	b.lock.Lock()
+	b.inflight--
+	if b.inflight == 0 {
+		b.idle.Broadcast()
+	}
+	b.lock.Unlock()
+}
+
+// close rejects new keys and flushes pending ones
+func (b *batcher) close() {
+	b.lock.Lock()
+	b.closed = true
+	b.lock.Unlock()
+	b.flush()
+}
+
+func (b *batcher) send(filterName string, bt *batch) {
+	cli, err := b.pool.Get()
+	if err != nil {
+		b.deliverError(filterName, bt, 0, err)
+		return
+	}
+	defer cli.Close()
+
+	f := cli.GetFilter(filterName)
+	reader := GetArrayReader(bt.keys)
+	var results ResultReader
+	if b.op == "b" {
+		results, err = f.BulkSet(reader)
+	} else {
+		results, err = f.MultiCheck(reader)
+	}
+	PutArrayReader(reader)
+	if err != nil {
+		b.deliverError(filterName, bt, 0, err)
+		return
+	}
+	defer results.Close()
+
+	for i, waiter := range bt.waiters {
+		found, err := results.Next()
+		if err != nil {
+			b.deliverError(filterName, bt, i, err)
+			return
+		}
+		if waiter != nil {
+			waiter <- batchResult{found: found}
+		}
+	}
+}
+
+// deliverError reports err to waiters of keys starting from i and to the error callback for keys nobody waits for
+func (b *batcher) deliverError(filterName string, bt *batch, i int, err error) {
+	var unreported []Key
+	for ; i < len(bt.keys); i++ {
+		if bt.waiters[i] != nil {
+			bt.waiters[i] <- batchResult{err: err}
+		} else {
+			unreported = append(unreported, bt.keys[i])
+		}
+	}
+	if len(unreported) > 0 && b.onError != nil {
+		b.onError(filterName, unreported, err)
+	}
+}
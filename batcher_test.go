@@ -0,0 +1,84 @@
+package bloomd_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/mock"
+)
+
+func TestBatcherCommands(t *testing.T) {
+	server, err := mock.NewListener("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Shutdown(context.Background())
+	pool, err := bloomd.NewPoolFromAddr(1, 5, server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	cli, err := pool.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cli.CreateFilter("batched", 0, 0, true); err != nil {
+		t.Fatal(err)
+	}
+	cli.Close()
+
+	// batchSizes returns sorted amounts of keys in commands with name, batches are sent concurrently
+	batchSizes := func(name string) []int {
+		var sizes []int
+		for _, cmd := range server.Commands() {
+			if cmd.Name == name {
+				sizes = append(sizes, len(cmd.Args))
+			}
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+		return sizes
+	}
+
+	t.Run("writer should send full batches and flush the rest", func(t *testing.T) {
+		server.ResetCommands()
+		w := bloomd.NewBatchWriter(pool, 10, time.Hour)
+		defer w.Close()
+		for i := 0; i < 25; i++ {
+			if err := w.SetAsync("batched", bloomd.Key(fmt.Sprintf("key_%d", i))); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w.Flush()
+		if sizes := batchSizes("b"); fmt.Sprint(sizes) != "[10 10 5]" {
+			t.Errorf("25 keys should be sent in batches of 10, 10 and 5 but got %v", sizes)
+		}
+		if sizes := batchSizes("s"); len(sizes) != 0 {
+			t.Errorf("keys should not be set one by one but %d were", len(sizes))
+		}
+	})
+
+	t.Run("checker should send concurrent checks in a single batch", func(t *testing.T) {
+		server.ResetCommands()
+		c := bloomd.NewBatchChecker(pool, 10, time.Hour)
+		defer c.Close()
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if _, err := c.Check("batched", bloomd.Key(fmt.Sprintf("key_%d", i))); err != nil {
+					t.Error(err)
+				}
+			}(i)
+		}
+		wg.Wait()
+		if sizes := batchSizes("m"); fmt.Sprint(sizes) != "[10]" {
+			t.Errorf("10 keys should be checked by a single command but got %v", sizes)
+		}
+	})
+}
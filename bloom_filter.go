@@ -0,0 +1,157 @@
+package bloomd
+
+import (
+	"context"
+)
+
+// BloomFilter is a common interface of filters regardless of their storage and windowing strategy
+// it is implemented by PoolFilter and filters adapted by WithContext
+// rolling and conveyor filters are bound to a pool by NewPoolFilter of their packages
+type BloomFilter interface {
+	Set(ctx context.Context, key Key) (bool, error)
+	Check(ctx context.Context, key Key) (bool, error)
+	BulkSet(ctx context.Context, reader KeyReader) (ResultReader, error)
+	MultiCheck(ctx context.Context, reader KeyReader) (ResultReader, error)
+}
+
+// KeyFilter is implemented by filters operating without context like Filter and local.Filter
+type KeyFilter interface {
+	Set(key Key) (bool, error)
+	Check(key Key) (bool, error)
+	BulkSet(reader KeyReader) (ResultReader, error)
+	MultiCheck(reader KeyReader) (ResultReader, error)
+}
+
+// WithContext adapts filter to BloomFilter, ctx is checked before every operation
+func WithContext(f KeyFilter) BloomFilter {
+	return contextFilter{f: f}
+}
+
+type contextFilter struct {
+	f KeyFilter
+}
+
+func (cf contextFilter) Set(ctx context.Context, key Key) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return cf.f.Set(key)
+}
+
+func (cf contextFilter) Check(ctx context.Context, key Key) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return cf.f.Check(key)
+}
+
+func (cf contextFilter) BulkSet(ctx context.Context, reader KeyReader) (ResultReader, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return cf.f.BulkSet(reader)
+}
+
+func (cf contextFilter) MultiCheck(ctx context.Context, reader KeyReader) (ResultReader, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return cf.f.MultiCheck(reader)
+}
+
+// BindFunc binds a filter to the client taken from a pool for an operation with ctx
+type BindFunc func(ctx context.Context, cli *Client) KeyFilter
+
+// PoolFilter is a filter accessed through a pool of clients
+// every operation takes a client from the pool, applies ctx deadline to its connection and binds the filter to it
+type PoolFilter struct {
+	pool *Pool
+	bind BindFunc
+}
+
+// NewPoolFilter creates a filter with name accessed through pool, it does not create the filter on the server
+func NewPoolFilter(pool *Pool, name string) *PoolFilter {
+	return BindPoolFilter(pool, func(ctx context.Context, cli *Client) KeyFilter {
+		return cli.GetFilter(name)
+	})
+}
+
+// BindPoolFilter creates a filter accessed through pool, bind is called with a client of the pool for every operation
+func BindPoolFilter(pool *Pool, bind BindFunc) *PoolFilter {
+	return &PoolFilter{
+		pool: pool,
+		bind: bind,
+	}
+}
+
+// Set sets a single key to the bloom
+func (pf *PoolFilter) Set(ctx context.Context, key Key) (bool, error) {
+	cli, err := pf.pool.GetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cli.Close()
+	return pf.bind(ctx, cli).Set(key)
+}
+
+// Check checks a single key in the bloom
+func (pf *PoolFilter) Check(ctx context.Context, key Key) (bool, error) {
+	cli, err := pf.pool.GetContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer cli.Close()
+	return pf.bind(ctx, cli).Check(key)
+}
+
+// BulkSet adds multiple keys to the filter, client is returned to the pool when results are closed
+func (pf *PoolFilter) BulkSet(ctx context.Context, reader KeyReader) (ResultReader, error) {
+	cli, err := pf.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := pf.bind(ctx, cli).BulkSet(reader)
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+	return ReleaseOnClose(results, cli), nil
+}
+
+// MultiCheck checks multiple keys for the filter, client is returned to the pool when results are closed
+func (pf *PoolFilter) MultiCheck(ctx context.Context, reader KeyReader) (ResultReader, error) {
+	cli, err := pf.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results, err := pf.bind(ctx, cli).MultiCheck(reader)
+	if err != nil {
+		cli.Close()
+		return nil, err
+	}
+	return ReleaseOnClose(results, cli), nil
+}
+
+// ReleaseOnClose returns reader which closes cli, returning it to its pool, once results are closed
+func ReleaseOnClose(results ResultReader, cli *Client) ResultReader {
+	return &releasingResultReader{
+		ResultReader: results,
+		client:       cli,
+	}
+}
+
+type releasingResultReader struct {
+	ResultReader
+	client *Client
+}
+
+func (r *releasingResultReader) Close() error {
+	err := r.ResultReader.Close()
+	if r.client != nil {
+		if cerr := r.client.Close(); err == nil {
+			err = cerr
+		}
+		r.client = nil
+	}
+	return err
+}
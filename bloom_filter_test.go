@@ -0,0 +1,109 @@
+package bloomd
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Applifier/go-bloomd/utils/testutils"
+)
+
+func TestPoolFilter(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		pool, err := NewPoolFromURL(1, 5, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		f, err := c.CreateFilter("pool_filter_"+url.Scheme, 0, 0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Drop()
+
+		filters := map[string]BloomFilter{
+			"pool":    NewPoolFilter(pool, f.Name),
+			"context": WithContext(f),
+		}
+		for name, bf := range filters {
+			t.Run(name, func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+
+				if _, err := bf.Set(ctx, Key("foo_"+name)); err != nil {
+					t.Fatal(err)
+				}
+				found, err := bf.Check(ctx, Key("foo_"+name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !found {
+					t.Error("key should be found")
+				}
+
+				results, err := bf.BulkSet(ctx, NewArrayReader(Key("bar_"+name), Key("baz_"+name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := results.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				results, err = bf.MultiCheck(ctx, NewArrayReader(Key("bar_"+name), Key("biz_"+name)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !next(t, results) || next(t, results) {
+					t.Error("Wrong responses received")
+				}
+				if err := results.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				cancel()
+				if _, err := bf.Check(ctx, Key("foo_"+name)); err != context.Canceled {
+					t.Errorf("canceled context should fail operation but was %v", err)
+				}
+			})
+		}
+
+		if pool.Len() != 1 {
+			t.Errorf("client should be returned to the pool, pool has %d connections", pool.Len())
+		}
+	})
+}
+
+func TestClientDeadline(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		pool, err := NewPoolFromURL(1, 1, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		c, err := pool.GetContext(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-ctx.Done()
+		if err := c.Ping(); err == nil {
+			t.Fatal("ping should fail after deadline")
+		}
+		c.Close()
+
+		c, err = pool.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		if err := c.Ping(); err != nil {
+			t.Fatalf("client from the pool should not inherit deadline or error (%s)", err)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package bloomd
+
+import (
+	"container/list"
+	"sync"
+)
+
+// Cache is a bounded LRU cache of keys known to be present in filters
+// bloom filters never unset a key, so a positive result stays valid until the filter is cleared or dropped
+// the cache is safe for concurrent use and can be shared by clients through Pool.SetCache
+type Cache struct {
+	lock    sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+	// generations of filters, cached entries of older generations are stale
+	generations    map[string]uint64
+	nextGeneration uint64
+}
+
+type cacheEntry struct {
+	key        string
+	generation uint64
+}
+
+// NewCache creates a cache holding up to size keys
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:           size,
+		entries:        make(map[string]*list.Element, size),
+		lru:            list.New(),
+		generations:    make(map[string]uint64),
+		nextGeneration: 1,
+	}
+}
+
+// Len returns amount of keys in the cache including stale ones
+func (c *Cache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+// contains returns true if key is known to be present in the filter
+func (c *Cache) contains(filterName string, key Key) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	el, ok := c.entries[cacheKey(filterName, key)]
+	if !ok {
+		return false
+	}
+	entry := el.Value.(*cacheEntry)
+	if entry.generation != c.generations[filterName] {
+		c.remove(el)
+		return false
+	}
+	c.lru.MoveToFront(el)
+	return true
+}
+
+// generation returns current generation of the filter, it should be taken before a request is sent
+func (c *Cache) generation(filterName string) uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.generations[filterName]
+}
+
+// add remembers that key is present in the filter
+// the key is not added if the filter was invalidated since generation was taken, as the result may be stale
+func (c *Cache) add(filterName string, key Key, generation uint64) {
+	if c.size <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if generation != c.generations[filterName] {
+		return
+	}
+	k := cacheKey(filterName, key)
+	if el, ok := c.entries[k]; ok {
+		el.Value.(*cacheEntry).generation = generation
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[k] = c.lru.PushFront(&cacheEntry{key: k, generation: generation})
+	if c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidate forgets all keys of the filter
+func (c *Cache) invalidate(filterName string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generations[filterName] = c.nextGeneration
+	c.nextGeneration++
+}
+
+func (c *Cache) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).key)
+}
+
+func cacheKey(filterName string, key Key) string {
+	return filterName + "\x00" + string(key)
+}
+
+// cachedResultReader merges results known from the cache with results read from the server
+// results read from the server are added to the cache if they prove that key is present
+type cachedResultReader struct {
+	cache      *Cache
+	filterName string
+	// generation of the filter taken before the request was sent
+	generation uint64
+	keys       []Key
+	// cached marks keys resolved from the cache, other keys are sent to the server in the same order
+	cached []bool
+	remote ResultReader
+	// setOp means that any result proves the key is present, otherwise only positive ones do
+	setOp  bool
+	cursor int
+}
+
+func (r *cachedResultReader) Next() (bool, error) {
+	if r.cursor >= len(r.keys) {
+		return false, ErrCursorOverLength
+	}
+	i := r.cursor
+	r.cursor++
+	if r.cached != nil && r.cached[i] {
+		return true, nil
+	}
+	result, err := r.remote.Next()
+	if err != nil {
+		return false, err
+	}
+	if result || r.setOp {
+		r.cache.add(r.filterName, r.keys[i], r.generation)
+	}
+	return result, nil
+}
+
+func (r *cachedResultReader) Read(p []bool) (n int, err error) {
+	for n < len(p) && r.cursor < len(r.keys) {
+		p[n], err = r.Next()
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (r *cachedResultReader) Length() int {
+	return len(r.keys)
+}
+
+// Close reads remaining results so keys proven to be present are still cached
+func (r *cachedResultReader) Close() error {
+	for r.cursor < len(r.keys) {
+		if _, err := r.Next(); err != nil {
+			r.cursor = len(r.keys)
+			break
+		}
+	}
+	if r.remote != nil {
+		return r.remote.Close()
+	}
+	return nil
+}
+
+// uncachedKeyReader iterates over keys not resolved from the cache
+type uncachedKeyReader struct {
+	keys   []Key
+	cached []bool
+	cursor int
+}
+
+func (r *uncachedKeyReader) Next() bool {
+	for r.cursor < len(r.keys) {
+		r.cursor++
+		if r.cached == nil || !r.cached[r.cursor-1] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *uncachedKeyReader) Current() Key {
+	return r.keys[r.cursor-1]
+}
+
+func copyKeys(reader KeyReader) []Key {
+	var keys []Key
+	for reader.Next() {
+		keys = append(keys, append(Key{}, reader.Current()...))
+	}
+	return keys
+}
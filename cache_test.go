@@ -0,0 +1,154 @@
+package bloomd
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/Applifier/go-bloomd/utils/testutils"
+)
+
+func TestCache(t *testing.T) {
+	t.Run("least recently used key should be evicted", func(t *testing.T) {
+		c := NewCache(2)
+		c.add("f", Key("foo"), 0)
+		c.add("f", Key("bar"), 0)
+		if !c.contains("f", Key("foo")) {
+			t.Fatal("foo should be cached")
+		}
+		c.add("f", Key("baz"), 0)
+		if c.contains("f", Key("bar")) {
+			t.Error("bar should be evicted")
+		}
+		if !c.contains("f", Key("foo")) || !c.contains("f", Key("baz")) {
+			t.Error("foo and baz should be cached")
+		}
+		if c.Len() != 2 {
+			t.Errorf("cache should contain 2 keys but contains %d", c.Len())
+		}
+	})
+
+	t.Run("invalidation should forget keys of a single filter", func(t *testing.T) {
+		c := NewCache(10)
+		c.add("f1", Key("foo"), 0)
+		c.add("f2", Key("foo"), 0)
+		c.invalidate("f1")
+		if c.contains("f1", Key("foo")) {
+			t.Error("foo should be forgotten for f1")
+		}
+		if !c.contains("f2", Key("foo")) {
+			t.Error("foo should be cached for f2")
+		}
+		c.add("f1", Key("foo"), c.generation("f1"))
+		if !c.contains("f1", Key("foo")) {
+			t.Error("foo should be cached for f1 again")
+		}
+	})
+
+	t.Run("results taken before invalidation should not be cached", func(t *testing.T) {
+		c := NewCache(10)
+		generation := c.generation("f")
+		c.invalidate("f")
+		c.add("f", Key("foo"), generation)
+		if c.contains("f", Key("foo")) {
+			t.Error("stale foo should not be cached")
+		}
+	})
+}
+
+func TestClientCache(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		c.SetCache(NewCache(100))
+
+		f, err := c.CreateFilter("cached_filter_"+url.Scheme, 0, 0, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Drop()
+
+		t.Run("multi check should ask only for missing keys and keep the order", func(t *testing.T) {
+			if _, err := f.Set(Key("foo")); err != nil {
+				t.Fatal(err)
+			}
+			results, err := f.BulkSet(NewArrayReader(Key("bar")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			results.Close()
+
+			checksBefore := infoValue(t, f, "checks")
+			results, err = f.MultiCheck(NewArrayReader(Key("biz"), Key("foo"), Key("baz"), Key("bar")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if results.Length() != 4 {
+				t.Fatalf("expected 4 results but got %d", results.Length())
+			}
+			if next(t, results) || !next(t, results) || next(t, results) || !next(t, results) {
+				t.Error("wrong results received")
+			}
+			results.Close()
+			if checks := infoValue(t, f, "checks"); checks != checksBefore+2 {
+				t.Errorf("only 2 keys should be checked by server but %d were", checks-checksBefore)
+			}
+
+			found, err := f.Check(Key("foo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Error("foo should be found")
+			}
+			if checks := infoValue(t, f, "checks"); checks != checksBefore+2 {
+				t.Error("cached key should not be checked by server")
+			}
+		})
+
+		t.Run("fully cached multi check should not reach the server", func(t *testing.T) {
+			checksBefore := infoValue(t, f, "checks")
+			results, err := f.MultiCheck(NewArrayReader(Key("foo"), Key("bar")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !next(t, results) || !next(t, results) {
+				t.Error("keys should be found")
+			}
+			results.Close()
+			if checks := infoValue(t, f, "checks"); checks != checksBefore {
+				t.Errorf("cached keys should not be checked by server but %d were", checks-checksBefore)
+			}
+		})
+
+		t.Run("drop should invalidate cached keys", func(t *testing.T) {
+			if err := f.Drop(); err != nil {
+				t.Fatal(err)
+			}
+			f, err = c.CreateFilter(f.Name, 0, 0, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			found, err := f.Check(Key("foo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found {
+				t.Error("foo should not be found after drop")
+			}
+		})
+	})
+}
+
+func infoValue(t *testing.T, f Filter, key string) int {
+	t.Helper()
+	info, err := f.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v int
+	if _, err := fmt.Sscan(info[key], &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/zap/buffer"
 
@@ -30,6 +31,8 @@ type Client struct {
 	reader       *bufio.Reader
 	writer       *bufio.Writer
 	err          error
+	hasDeadline  bool
+	cache        *Cache
 
 	clientPool *sync.Pool
 }
@@ -109,6 +112,67 @@ func (cli *Client) ListFilters() ([]Filter, error) {
 	return filters, nil
 }
 
+// FilterStats describes a filter as reported by the list command
+type FilterStats struct {
+	Name string
+	Prob float64
+	// Storage is amount of bytes allocated for the filter
+	Storage  uint64
+	Capacity int
+	Size     int
+}
+
+// ListFilterStats returns stats of all filters
+func (cli *Client) ListFilterStats() ([]FilterStats, error) {
+	if err := cli.send([]byte("list")); err != nil {
+		return nil, err
+	}
+
+	filterLines, err := cli.readList()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]FilterStats, len(filterLines))
+	for i, filterLine := range filterLines {
+		if stats[i], err = parseFilterStats(filterLine); err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
+
+func parseFilterStats(line string) (FilterStats, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 5 {
+		return FilterStats{}, Error{Message: "invalid filter line received from server: " + line}
+	}
+	prob, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return FilterStats{}, Error{Message: "invalid filter line received from server: " + line, Err: err}
+	}
+	storage, err := strconv.ParseUint(fields[2], 10, 64)
+	if err != nil {
+		return FilterStats{}, Error{Message: "invalid filter line received from server: " + line, Err: err}
+	}
+	capacity, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return FilterStats{}, Error{Message: "invalid filter line received from server: " + line, Err: err}
+	}
+	size, err := strconv.Atoi(fields[4])
+	if err != nil {
+		return FilterStats{}, Error{Message: "invalid filter line received from server: " + line, Err: err}
+	}
+	return FilterStats{
+		Name:     fields[0],
+		Prob:     prob,
+		Storage:  storage,
+		Capacity: capacity,
+		Size:     size,
+	}, nil
+}
+
 // GetFilter returns a previously created filter
 func (cli *Client) GetFilter(name string) Filter {
 	return Filter{
@@ -167,6 +231,9 @@ func (cli *Client) Close() error {
 		if pc, ok := cli.conn.(*pool.PoolConn); ok {
 			pc.MarkUnusable()
 		}
+	} else if cli.hasDeadline {
+		// connection might be reused from the pool, so deadline should not outlive the client
+		cli.SetDeadline(time.Time{})
 	}
 
 	err := cli.conn.Close()
@@ -179,6 +246,19 @@ func (cli *Client) Close() error {
 	return err
 }
 
+// SetCache enables caching of keys known to be present in filters, nil disables caching
+// Check and MultiCheck answer cached keys without asking the server,
+// Clear and Drop through this client forget cached keys of the filter
+func (cli *Client) SetCache(cache *Cache) {
+	cli.cache = cache
+}
+
+// SetDeadline sets read and write deadline for the underlying connection, zero value of t removes the deadline
+func (cli *Client) SetDeadline(t time.Time) error {
+	cli.hasDeadline = !t.IsZero()
+	return cli.conn.SetDeadline(t)
+}
+
 // Ping pings the server
 func (cli *Client) Ping() error {
 	resp, err := cli.sendAndReceive([]byte("ping"))
@@ -194,6 +274,8 @@ func (cli *Client) Ping() error {
 
 func (cli *Client) reset(conn net.Conn) {
 	cli.conn = conn
+	cli.err = nil
+	cli.hasDeadline = false
 	cli.reader.Reset(conn)
 	cli.writer.Reset(conn)
 	cli.resultReader.client = cli
@@ -21,6 +21,47 @@ func TestClientConnect(t *testing.T) {
 	}
 }
 
+func TestListFilterStats(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		f, err := c.CreateFilter("list_filter_stats_"+url.Scheme, 20000, 0.01, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Drop()
+		results, err := f.BulkSet(NewArrayReader(Key("foo"), Key("bar")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		results.Close()
+
+		stats, err := c.ListFilterStats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range stats {
+			if s.Name != f.Name {
+				continue
+			}
+			if s.Capacity != 20000 || s.Prob != 0.01 || s.Size != 2 || s.Storage == 0 {
+				t.Errorf("wrong stats %+v", s)
+			}
+			return
+		}
+		t.Errorf("%s is not listed", f.Name)
+	})
+}
+
+func TestParseFilterStats(t *testing.T) {
+	if _, err := parseFilterStats("foo 0.01 1024 20000"); err == nil {
+		t.Error("line without size should fail")
+	}
+	if _, err := parseFilterStats("foo 0.01 1024 20000 bar"); err == nil {
+		t.Error("line with invalid size should fail")
+	}
+}
+
 func TestClientConnectionError(t *testing.T) {
 	t.Run("Tcp addr has no port", func(t *testing.T) {
 		_, err := NewFromAddr("tcp://foo")
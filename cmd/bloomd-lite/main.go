@@ -0,0 +1,110 @@
+// Command bloomd-lite is a bloomd compatible server backed by the in memory filter store of the mock package.
+// It is meant to be used as a local stand-in for bloomd by developers and CI.
+//
+// Usage:
+//
+//	bloomd-lite -port 8673 -socket /tmp/bloomd.sock -snapshot-dir /var/lib/bloomd-lite
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Applifier/go-bloomd/mock"
+)
+
+var (
+	host            = flag.String("host", "", "host to bind TCP listener to, all interfaces by default")
+	port            = flag.Int("port", 8673, "TCP port to listen on, 0 disables TCP listener")
+	socket          = flag.String("socket", "", "path of unix socket to listen on, empty disables unix listener")
+	snapshotDir     = flag.String("snapshot-dir", "", "directory to keep filters that are not in memory, they are written on flush, close and shutdown, empty disables persistence")
+	probabilistic   = flag.Bool("probabilistic", false, "back filters by bloom filters producing false positives instead of exact sets")
+	shutdownTimeout = flag.Duration("shutdown-timeout", 5*time.Second, "time to wait for in flight commands on shutdown")
+)
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	store := mock.NewStore()
+	if *snapshotDir != "" {
+		var err error
+		if store, err = mock.NewPersistentStore(*snapshotDir); err != nil {
+			return fmt.Errorf("error: could not load snapshot (%s)", err)
+		}
+	}
+	store.SetProbabilistic(*probabilistic)
+
+	listeners, err := listen()
+	if err != nil {
+		return err
+	}
+
+	server := mock.NewServer(store)
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		log.Printf("bloomd-lite: listening on %s://%s", l.Addr().Network(), l.Addr())
+		go func(l net.Listener) {
+			errs <- server.Serve(l)
+		}(l)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-signals:
+		log.Printf("bloomd-lite: received %s, shutting down", sig)
+	case err = <-errs:
+		log.Printf("bloomd-lite: listener failed (%s), shutting down", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if serr := server.Shutdown(ctx); serr != nil {
+		log.Printf("bloomd-lite: shutdown error (%s)", serr)
+	}
+	if serr := store.Flush(); serr != nil {
+		return fmt.Errorf("error: could not flush filters (%s)", serr)
+	}
+	return err
+}
+
+func listen() ([]net.Listener, error) {
+	var listeners []net.Listener
+	if *port > 0 {
+		l, err := net.Listen("tcp", net.JoinHostPort(*host, fmt.Sprint(*port)))
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	if *socket != "" {
+		l, err := net.Listen("unix", *socket)
+		if err != nil {
+			closeAll(listeners)
+			return nil, err
+		}
+		listeners = append(listeners, l)
+	}
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("error: neither port nor socket is configured")
+	}
+	return listeners, nil
+}
+
+func closeAll(listeners []net.Listener) {
+	for _, l := range listeners {
+		l.Close()
+	}
+}
@@ -0,0 +1,39 @@
+package aggregation
+
+import (
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+// CountsSet is a results set which also counts in how many readers each key was found
+// read as a results set it reports whether key was found in any of readers
+type CountsSet struct {
+	*ResultsSet
+	counts []int
+}
+
+// GetCountsSet fetches results set of provided length from pool, no key is found in it yet
+func GetCountsSet(length int) *CountsSet {
+	cs := &CountsSet{
+		ResultsSet: GetResultSet(length),
+		counts:     make([]int, length),
+	}
+	for i := 0; i < length; i++ {
+		cs.set(i, false)
+	}
+	return cs
+}
+
+// CountFromReader merges results from reader using "or" logical operation and counts found keys
+func (cs *CountsSet) CountFromReader(reader bloomd.ResultReader) error {
+	return cs.readFromReader(reader, func(i int, val bool) {
+		cs.swapIf(i, val)
+		if val {
+			cs.counts[i]++
+		}
+	})
+}
+
+// Count returns in how many readers i-th key was found
+func (cs *CountsSet) Count(i int) int {
+	return cs.counts[i]
+}
@@ -0,0 +1,49 @@
+package aggregation
+
+import (
+	"testing"
+)
+
+func TestCountsSet(t *testing.T) {
+	cs := GetCountsSet(3)
+	defer cs.Close()
+	readers := []*resultsReaderMock{
+		newResultsReaderMock(true, false, false),
+		newResultsReaderMock(true, false, true),
+		newResultsReaderMock(true, false, false),
+	}
+	for _, reader := range readers {
+		if err := cs.CountFromReader(reader); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []int{3, 0, 1}
+	for i := 0; i < len(expected); i++ {
+		if cs.Count(i) != expected[i] {
+			t.Errorf("expected count of %d key to be %d but was %d", i, expected[i], cs.Count(i))
+		}
+	}
+	var result [3]bool
+	if _, err := cs.Read(result[:]); err != nil {
+		t.Fatal(err)
+	}
+	if !result[0] || result[1] || !result[2] {
+		t.Errorf("keys found in any reader expected but got %v", result)
+	}
+
+	if err := cs.CountFromReader(newResultsReaderMock(true)); err == nil {
+		t.Error("short reader should fail")
+	}
+}
+
+func TestCountsSetReset(t *testing.T) {
+	rs := GetResultSet(2)
+	rs.FillFromReader(newResultsReaderMock(true, true))
+	rs.Close()
+	cs := GetCountsSet(2)
+	defer cs.Close()
+	if v, _ := cs.Next(); v || cs.Count(0) != 0 {
+		t.Error("counts set from pool should be empty")
+	}
+}
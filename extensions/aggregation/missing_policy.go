@@ -0,0 +1,42 @@
+package aggregation
+
+import (
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// MissingFilterPolicy defines how checks of rolling and conveyor filters treat filters of the period that do not exist
+type MissingFilterPolicy int
+
+const (
+	// MissingFilterError makes checks fail with bloomd.ErrFilterNotExist, it is the default policy
+	MissingFilterError MissingFilterPolicy = iota
+	// MissingFilterEmpty makes checks treat missing filters as if they contain no keys
+	MissingFilterEmpty
+	// MissingFilterCreate makes checks treat missing filters as empty and create them afterwards
+	MissingFilterCreate
+)
+
+// Results applies the policy to results of the filter of unit
+// units of missing filters are appended to missing
+func (p MissingFilterPolicy) Results(reader bloomd.ResultReader, unit clock.UnitNum, missing *[]clock.UnitNum) bloomd.ResultReader {
+	if p == MissingFilterError {
+		return reader
+	}
+	return MissingAsEmpty(reader, func() {
+		*missing = append(*missing, unit)
+	})
+}
+
+// CreateMissing calls create with cli for units of missing filters if the policy requires it
+func (p MissingFilterPolicy) CreateMissing(cli *bloomd.Client, missing []clock.UnitNum, create func(cli *bloomd.Client, unit clock.UnitNum) error) error {
+	if p != MissingFilterCreate {
+		return nil
+	}
+	for _, unit := range missing {
+		if err := create(cli, unit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package aggregation
+
+import (
+	"testing"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+func TestMissingFilterPolicy(t *testing.T) {
+	t.Run("error policy should keep results intact", func(t *testing.T) {
+		var missing []clock.UnitNum
+		reader := MissingFilterError.Results(&missingReaderMock{*newResultsReaderMock(true)}, 5, &missing)
+		if _, err := reader.Next(); err != bloomd.ErrFilterNotExist {
+			t.Errorf("ErrFilterNotExist expected but got %v", err)
+		}
+		if len(missing) != 0 {
+			t.Errorf("no missing units expected but got %v", missing)
+		}
+	})
+
+	for _, policy := range []MissingFilterPolicy{MissingFilterEmpty, MissingFilterCreate} {
+		var missing []clock.UnitNum
+		reader := policy.Results(&missingReaderMock{*newResultsReaderMock(true)}, 5, &missing)
+		if found, err := reader.Next(); err != nil || found {
+			t.Errorf("policy %d should read missing filter as empty but got %v, %v", policy, found, err)
+		}
+		var created []clock.UnitNum
+		err := policy.CreateMissing(nil, missing, func(cli *bloomd.Client, unit clock.UnitNum) error {
+			created = append(created, unit)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if policy == MissingFilterCreate && (len(created) != 1 || created[0] != 5) {
+			t.Errorf("filter of unit 5 expected to be created but got %v", created)
+		}
+		if policy == MissingFilterEmpty && len(created) != 0 {
+			t.Errorf("no filters expected to be created but got %v", created)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package aggregation
+
+import (
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+// MissingReader reads results of a filter that does not exist as if none of the keys were found
+type MissingReader struct {
+	reader    bloomd.ResultReader
+	onMissing func()
+	missing   bool
+	cursor    int
+}
+
+// MissingAsEmpty wraps reader so that bloomd.ErrFilterNotExist is read as all No
+// onMissing is called once the filter is found to be missing, it can be nil
+func MissingAsEmpty(reader bloomd.ResultReader, onMissing func()) *MissingReader {
+	return &MissingReader{
+		reader:    reader,
+		onMissing: onMissing,
+	}
+}
+
+// Missing returns true if the filter was found to be missing
+func (mr *MissingReader) Missing() bool {
+	return mr.missing
+}
+
+// Next returns next result
+func (mr *MissingReader) Next() (bool, error) {
+	if mr.missing {
+		if mr.cursor >= mr.Length() {
+			return false, ErrCursorOverLength
+		}
+		mr.cursor++
+		return false, nil
+	}
+	result, err := mr.reader.Next()
+	if err == bloomd.ErrFilterNotExist && mr.cursor == 0 {
+		mr.missing = true
+		mr.cursor++
+		if mr.onMissing != nil {
+			mr.onMissing()
+		}
+		return false, nil
+	}
+	if err == nil {
+		mr.cursor++
+	}
+	return result, err
+}
+
+// Read reads results into provided array
+func (mr *MissingReader) Read(p []bool) (int, error) {
+	n := mr.Length() - mr.cursor
+	if len(p) < n {
+		n = len(p)
+	}
+	for i := 0; i < n; i++ {
+		next, err := mr.Next()
+		if err != nil {
+			return i, err
+		}
+		p[i] = next
+	}
+	return n, nil
+}
+
+// Length returns amount of results
+func (mr *MissingReader) Length() int {
+	return mr.reader.Length()
+}
+
+// Close closes underlying reader
+func (mr *MissingReader) Close() error {
+	return mr.reader.Close()
+}
@@ -0,0 +1,55 @@
+package aggregation
+
+import (
+	"testing"
+
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+type missingReaderMock struct {
+	resultsReaderMock
+}
+
+func (mrm *missingReaderMock) Next() (bool, error) {
+	return false, bloomd.ErrFilterNotExist
+}
+
+func TestMissingReader(t *testing.T) {
+	t.Run("existing filter", func(t *testing.T) {
+		mr := MissingAsEmpty(newResultsReaderMock(true, false), func() {
+			t.Error("filter should not be missing")
+		})
+		rs := GetResultSet(2)
+		defer rs.Close()
+		if err := rs.FillFromReader(mr); err != nil {
+			t.Fatal(err)
+		}
+		if mr.Missing() {
+			t.Error("filter should not be missing")
+		}
+		if v, _ := rs.Next(); !v {
+			t.Error("first key should be found")
+		}
+	})
+
+	t.Run("missing filter", func(t *testing.T) {
+		calls := 0
+		mr := MissingAsEmpty(&missingReaderMock{*newResultsReaderMock(true, true, true)}, func() {
+			calls++
+		})
+		var result [3]bool
+		n, err := mr.Read(result[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 3 || result[0] || result[1] || result[2] {
+			t.Errorf("3 negative results expected but got %v", result[:n])
+		}
+		if !mr.Missing() || calls != 1 {
+			t.Errorf("filter should be reported missing once but was reported %d times", calls)
+		}
+		if _, err := mr.Next(); err != ErrCursorOverLength {
+			t.Errorf("cursor over length expected but got %v", err)
+		}
+	})
+}
@@ -34,26 +34,23 @@ func GetResultSet(length int) *ResultsSet {
 
 // FillFromReader makes initial load for result set from provided reader
 func (rs *ResultsSet) FillFromReader(reader bloomd.ResultReader) error {
-	defer reader.Close()
-	for i := 0; i < rs.Length(); i++ {
-		next, err := reader.Next()
-		if err != nil {
-			return err
-		}
-		rs.set(i, next)
-	}
-	return nil
+	return rs.readFromReader(reader, rs.set)
 }
 
 // MergeFromReader merge results from reader with results that are already in set, merge is made for each corresponding pair using "or" logical operation
 func (rs *ResultsSet) MergeFromReader(reader bloomd.ResultReader) error {
+	return rs.readFromReader(reader, rs.swapIf)
+}
+
+// readFromReader reads result for every key of the set from reader and passes it to merge
+func (rs *ResultsSet) readFromReader(reader bloomd.ResultReader, merge func(i int, val bool)) error {
 	defer reader.Close()
 	for i := 0; i < rs.Length(); i++ {
 		next, err := reader.Next()
 		if err != nil {
 			return err
 		}
-		rs.swapIf(i, next)
+		merge(i, next)
 	}
 	return nil
 }
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/aggregation"
 	"github.com/Applifier/go-bloomd/extensions/input"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
 	"github.com/Applifier/go-bloomd/utils/clock"
 )
 
@@ -23,6 +25,10 @@ const (
 	ShiftWeekly = clock.Unit("w")
 	// ShiftMonthly roll filter every month
 	ShiftMonthly = clock.Unit("m")
+	// ShiftHourly roll filter every hour
+	ShiftHourly = clock.HourUnit
+	// ShiftMinutely roll filter every minute
+	ShiftMinutely = clock.MinuteUnit
 )
 
 // Filter provides fuctionality of working with multiple sequential filters through time
@@ -31,97 +37,244 @@ type Filter struct {
 	period   clock.UnitNum
 	unit     clock.Unit
 	currUnit func() clock.UnitNum
+	calendar *clock.Calendar
+	clock    clock.Clock
+	// maxPeriod is the widest period accepted by NewFilter
+	maxPeriod clock.UnitNum
+	// missingPolicy defines how checks treat filters that do not exist
+	missingPolicy aggregation.MissingFilterPolicy
+	// spec is a template of filters created on demand
+	spec *ondemand.FilterSpec
+	// creator creates filters on demand once per unit
+	creator ondemand.Creator
 }
 
-var currUnitMap = map[clock.Unit]func() clock.UnitNum{
-	ShiftDaily:   clock.DayNum,
-	ShiftWeekly:  clock.WeekNum,
-	ShiftMonthly: clock.MonthNum,
+// DefaultMaxPeriod is the widest period accepted by NewFilter unless WithMaxPeriod is used
+var DefaultMaxPeriod = clock.UnitNum(400)
+
+// Option configures a Filter
+type Option func(rf *Filter)
+
+// WithClock makes filter take current time from clk instead of the global clock
+func WithClock(clk clock.Clock) Option {
+	return func(rf *Filter) {
+		rf.clock = clk
+	}
+}
+
+// WithMaxPeriod overrides DefaultMaxPeriod for the filter
+func WithMaxPeriod(maxPeriod clock.UnitNum) Option {
+	return func(rf *Filter) {
+		rf.maxPeriod = maxPeriod
+	}
+}
+
+// WithMissingFilterPolicy sets how checks treat missing filters, so windows can start without creating the full history
+func WithMissingFilterPolicy(policy aggregation.MissingFilterPolicy) Option {
+	return func(rf *Filter) {
+		rf.missingPolicy = policy
+	}
+}
+
+// WithCalendar makes filter compute units in the calendar, e.g. to roll filters at local midnight
+func WithCalendar(calendar *clock.Calendar) Option {
+	return func(rf *Filter) {
+		rf.calendar = calendar
+	}
+}
+
+var unitNumOfMap = map[clock.Unit]func(t time.Time) clock.UnitNum{
+	ShiftDaily:    clock.DayNumOf,
+	ShiftWeekly:   clock.WeekNumOf,
+	ShiftMonthly:  clock.MonthNumOf,
+	ShiftHourly:   clock.HourNumOf,
+	ShiftMinutely: clock.MinuteNumOf,
 }
 
 // NewFilter creates a new Filter
-// unit - unit of time, e.g. Week, Day or Month. All keys being set during period with a same unit will be stored in a single filter.
-// period - period in specified time units to consider in set operations
+// unit - unit of time, e.g. Month, Week, Day, Hour, Minute or a duration unit created by clock.DurationUnit. All keys being set during period with a same unit will be stored in a single filter.
+// period - period in specified time units to consider in set operations, at least 1
 // namer - provides algorithm to name filters according to specified unit of time
-func NewFilter(namer Namer, unit clock.Unit, period clock.UnitNum) (*Filter, error) {
-	currUnitFunc, ok := currUnitMap[unit]
-	if !ok {
-		return nil, fmt.Errorf("Unit %s does not supported", unit)
+// opts - optional settings of the filter
+func NewFilter(namer Namer, unit clock.Unit, period clock.UnitNum, opts ...Option) (*Filter, error) {
+	rf := &Filter{
+		namer:     namer,
+		unit:      unit,
+		period:    period,
+		clock:     clock.Global,
+		maxPeriod: DefaultMaxPeriod,
 	}
-	if period >= 100 { // TODO there should be tests to find a good max period
-		return nil, fmt.Errorf("Too wide period")
+	for _, opt := range opts {
+		opt(rf)
 	}
-	return &Filter{
-		namer:    namer,
-		unit:     unit,
-		period:   period,
-		currUnit: currUnitFunc,
-	}, nil
+	if period < 1 {
+		return nil, fmt.Errorf("Too narrow period %d, period should be at least 1", period)
+	}
+	if period > rf.maxPeriod {
+		return nil, fmt.Errorf("Too wide period %d, max period is %d", period, rf.maxPeriod)
+	}
+	unitNumOf, err := rf.unitNumOfFunc()
+	if err != nil {
+		return nil, err
+	}
+	rf.currUnit = func() clock.UnitNum {
+		return unitNumOf(rf.clock.Now())
+	}
+	return rf, nil
+}
+
+func (rf *Filter) unitNumOfFunc() (func(t time.Time) clock.UnitNum, error) {
+	if rf.calendar != nil {
+		unitNumOf, err := rf.calendar.NumOfFunc(rf.unit)
+		if err != nil {
+			return nil, fmt.Errorf("Unit %s does not supported", rf.unit)
+		}
+		return unitNumOf, nil
+	}
+	if unitNumOf, ok := unitNumOfMap[rf.unit]; ok {
+		return unitNumOf, nil
+	}
+	buckets, err := clock.ParseDurationUnit(rf.unit)
+	if err != nil {
+		return nil, fmt.Errorf("Unit %s does not supported", rf.unit)
+	}
+	return buckets.NumOf, nil
 }
 
 // BulkSet sets keys to all filters within the configured period
 // it returns result for oldest filter
-// note that it does not check if filter exists
+// commands to all filters are pipelined, so the whole period costs a single round trip
+// if filter spec is set missing filters are created and keys are set to them once again
 func (rf *Filter) BulkSet(ctx context.Context, cli *bloomd.Client, rr input.KeyReaderReseter) (results bloomd.ResultReader, err error) {
 	deadline, checkDeadline := ctx.Deadline()
+	if checkDeadline && deadline.Before(time.Now()) {
+		return nil, context.DeadlineExceeded
+	}
 	currUnit := rf.currUnit()
+	pipeline := cli.Pipeline()
 	for i := clock.UnitZero; i < rf.period; i++ {
+		pipeline.BulkSet(rf.nameForUnit(currUnit-i), input.Replay(rr))
+	}
+	oldestUnit := currUnit - rf.period + 1
+	var (
+		rs      *aggregation.ResultsSet
+		missing []clock.UnitNum
+	)
+	err = pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
 		if checkDeadline && deadline.Before(time.Now()) {
-			return nil, context.DeadlineExceeded
-		}
-		f := cli.GetFilter(rf.nameForUnit(currUnit - i))
-		reader, err := f.BulkSet(rr)
-		if err != nil {
-			return nil, err
+			return context.DeadlineExceeded
 		}
+		unit := currUnit - clock.UnitNum(i)
+		reader = rf.retryable(reader, unit, &missing)
 		// return bulk set result for oldest unit
-		if i == rf.period-1 {
-			results = reader
-		} else {
-			reader.Close()
-			rr.Reset()
+		if unit == oldestUnit {
+			rs = aggregation.GetResultSet(reader.Length())
+			return rs.FillFromReader(reader)
+		}
+		if rf.spec != nil {
+			// results of other units are read only to find missing filters
+			_, err := reader.Next()
+			return err
 		}
+		return nil
+	})
+	rr.Reset()
+	if err == nil && len(missing) > 0 {
+		err = rf.retryMissing(cli, missing, func(p *bloomd.Pipeline, name string) {
+			p.BulkSet(name, input.Replay(rr))
+		}, func(unit clock.UnitNum, reader bloomd.ResultReader) error {
+			if unit == oldestUnit {
+				return rs.FillFromReader(reader)
+			}
+			return nil
+		})
+		rr.Reset()
 	}
-	return results, err
+	if err != nil {
+		if rs != nil {
+			rs.Close()
+		}
+		return nil, err
+	}
+	return rs, nil
 }
 
 // MultiCheck checks keys in the oldest filter
-// note that it does not check if filters exist
+// missing filter is treated according to the missing filter policy, results are read into memory to create it
 func (rf *Filter) MultiCheck(ctx context.Context, cli *bloomd.Client, reader bloomd.KeyReader) (resultReader bloomd.ResultReader, err error) {
 	currUnit := rf.currUnit()
 	oldestUnit := currUnit - rf.period + 1
 	f := cli.GetFilter(rf.nameForUnit(oldestUnit))
-	return f.MultiCheck(reader)
+	results, err := f.MultiCheck(reader)
+	if err != nil || rf.missingPolicy == aggregation.MissingFilterError {
+		return results, err
+	}
+	var missing []clock.UnitNum
+	results = rf.missingPolicy.Results(results, oldestUnit, &missing)
+	if rf.missingPolicy != aggregation.MissingFilterCreate {
+		return results, nil
+	}
+	rs := aggregation.GetResultSet(results.Length())
+	err = rs.FillFromReader(results)
+	if err == nil {
+		err = rf.missingPolicy.CreateMissing(cli, missing, rf.createOnce)
+	}
+	if err != nil {
+		rs.Close()
+		return nil, err
+	}
+	return rs, nil
 }
 
 // Set sets key to all filters within the configured period
 // returns result for set into oldest filter
-// note that it does not check if filter exists
+// if filter spec is set missing filters are created and key is set to them once again
 func (rf *Filter) Set(ctx context.Context, cli *bloomd.Client, k bloomd.Key) (result bool, err error) {
 	deadline, checkDeadline := ctx.Deadline()
+	if checkDeadline && deadline.Before(time.Now()) {
+		return false, context.DeadlineExceeded
+	}
 	currUnit := rf.currUnit()
+	pipeline := cli.Pipeline()
 	for i := clock.UnitZero; i < rf.period; i++ {
-		if checkDeadline && deadline.Before(time.Now()) {
-			return false, context.DeadlineExceeded
-		}
-		fName := rf.nameForUnit(currUnit - i)
-		f := cli.GetFilter(fName)
+		pipeline.Set(rf.nameForUnit(currUnit-i), k)
+	}
+	var missing []clock.UnitNum
+	err = pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
 		// result will contain set result for the oldest filter
-		result, err = f.Set(k)
-		if err != nil {
-			return false, err
-		}
+		res, err := rf.retryable(reader, currUnit-clock.UnitNum(i), &missing).Next()
+		result = res
+		return err
+	})
+	if err == nil && len(missing) > 0 {
+		oldestUnit := currUnit - rf.period + 1
+		err = rf.retryMissing(cli, missing, func(p *bloomd.Pipeline, name string) {
+			p.Set(name, k)
+		}, func(unit clock.UnitNum, reader bloomd.ResultReader) error {
+			res, err := reader.Next()
+			if unit == oldestUnit {
+				result = res
+			}
+			return err
+		})
 	}
-	return result, err
+	if err != nil {
+		return false, err
+	}
+	return result, nil
 }
 
-// Check sequentially checks filters through period
-// note that it does not check if filters exist
+// Check checks key in the oldest filter
+// missing filter is treated according to the missing filter policy
 func (rf *Filter) Check(ctx context.Context, cli *bloomd.Client, k bloomd.Key) (bool, error) {
 	currUnit := rf.currUnit()
 	oldestUnit := currUnit - rf.period + 1
 	f := cli.GetFilter(rf.nameForUnit(oldestUnit))
-	return f.Check(k)
+	found, err := f.Check(k)
+	if err == bloomd.ErrFilterNotExist && rf.missingPolicy != aggregation.MissingFilterError {
+		return false, rf.missingPolicy.CreateMissing(cli, []clock.UnitNum{oldestUnit}, rf.createOnce)
+	}
+	return found, err
 }
 
 // Drop drops all filters through period
@@ -246,6 +399,26 @@ type unitFilter struct {
 	unit   clock.UnitNum
 }
 
+// CurrentUnit returns the unit of the current time
+func (rf *Filter) CurrentUnit() clock.UnitNum {
+	return rf.currUnit()
+}
+
+// Period returns amount of units considered by the filter
+func (rf *Filter) Period() clock.UnitNum {
+	return rf.period
+}
+
+// NameFor returns name of the filter of the unit
+func (rf *Filter) NameFor(unit clock.UnitNum) string {
+	return rf.nameForUnit(unit)
+}
+
+// ParseUnit returns unit of the filter name, it fails if the name does not belong to the filter
+func (rf *Filter) ParseUnit(name string) (clock.UnitNum, error) {
+	return rf.namer.ParseUnit(name)
+}
+
 func (rf *Filter) nameForUnit(unit clock.UnitNum) string {
 	return rf.namer.NameFor(unit)
 }
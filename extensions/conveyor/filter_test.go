@@ -7,8 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Applifier/go-bloomd/extensions/aggregation"
 	"github.com/Applifier/go-bloomd/extensions/input"
 	"github.com/Applifier/go-bloomd/extensions/namer"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
 	"github.com/Applifier/go-bloomd/utils/period"
 
 	"github.com/Applifier/go-bloomd/utils/clock"
@@ -22,7 +24,7 @@ var manageOperationTimeout = 1 * time.Second
 
 // var runtimeOperationTimeout = 10 * time.Millisecond
 var runtimeOperationTimeout = 10 * time.Minute
-var units = []clock.Unit{ShiftDaily, ShiftMonthly, ShiftWeekly}
+var units = []clock.Unit{ShiftDaily, ShiftMonthly, ShiftWeekly, ShiftHourly, ShiftMinutely}
 
 func TestOperations(t *testing.T) {
 	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
@@ -201,13 +203,159 @@ func TestFiltersManagement(t *testing.T) {
 	})
 }
 
+func TestDurationUnit(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		now := time.Now()
+		clock.Static(now)
+		defer clock.Reset()
+		step := 4 * time.Hour
+		rf := createFilter(t, c, "test_duration_unit_"+url.Scheme, 2, clock.DurationUnit(step))
+		defer dropFilter(t, c, rf)
+
+		ctx := getContext(manageOperationTimeout)
+		if _, err := rf.Set(ctx, c, bloomd.Key("foo")); err != nil {
+			t.Fatal(err)
+		}
+		for i, expected := range []bool{true, true, false} {
+			clock.Static(now.Add(time.Duration(i) * step))
+			if err := rf.CreateFilters(ctx, c, 0, 0, 0, true); err != nil {
+				t.Fatal(err)
+			}
+			found, err := rf.Check(ctx, c, bloomd.Key("foo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != expected {
+				t.Errorf("foo is expected to be found %t after %d buckets", expected, i)
+			}
+		}
+	})
+}
+
+func TestMissingFilterPolicy(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		clk := clock.NewManualClock(time.Now())
+		namer := namer.MustNewTimeUnitNamer("test_missing_filter_policy_"+url.Scheme, ShiftDaily)
+		filterWithPolicy := func(policy aggregation.MissingFilterPolicy) *Filter {
+			rf, err := NewFilter(namer, ShiftDaily, 3, WithClock(clk), WithMissingFilterPolicy(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return rf
+		}
+		rf := filterWithPolicy(aggregation.MissingFilterError)
+		defer dropFilter(t, c, rf)
+
+		if _, err := rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("foo")); err != bloomd.ErrFilterNotExist {
+			t.Errorf("ErrFilterNotExist expected but got %v", err)
+		}
+
+		rf = filterWithPolicy(aggregation.MissingFilterEmpty)
+		results, err := rf.MultiCheck(getContext(manageOperationTimeout), c, bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := make([]bool, 2)
+		if _, err := results.Read(found); err != nil {
+			t.Fatal(err)
+		}
+		results.Close()
+		if found[0] || found[1] {
+			t.Errorf("nothing should be found in missing filter but got %v", found)
+		}
+
+		rf = filterWithPolicy(aggregation.MissingFilterCreate)
+		results, err = rf.MultiCheck(getContext(manageOperationTimeout), c, bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		results.Close()
+		if _, err := rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("foo")); err != nil {
+			t.Errorf("oldest filter should be created but got %v", err)
+		}
+	})
+}
+
+func TestFilterSpec(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		clk := clock.NewManualClock(time.Now())
+		namer := namer.MustNewTimeUnitNamer("test_filter_spec_"+url.Scheme, ShiftDaily)
+		rf, err := NewFilter(namer, ShiftDaily, 3, WithClock(clk), WithFilterSpec(ondemand.FilterSpec{InMemory: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dropFilter(t, c, rf)
+
+		added, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("foo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !added {
+			t.Error("foo should be added to the oldest filter")
+		}
+		fs, err := rf.findFilters(getContext(manageOperationTimeout), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(fs) != 3 {
+			t.Errorf("all 3 filters of the period should be created but there are %d filters", len(fs))
+		}
+
+		clk.Advance(period.Day)
+		results, err := rf.BulkSet(getContext(manageOperationTimeout), c, readerReseter("foo", "bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := make([]bool, 2)
+		if _, err := results.Read(found); err != nil {
+			t.Fatal(err)
+		}
+		results.Close()
+		if found[0] || !found[1] {
+			t.Errorf("only bar should be new in the oldest filter but got %v", found)
+		}
+		found[0], err = rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found[0] {
+			t.Error("bar should be found")
+		}
+	})
+}
+
+func TestMaxPeriod(t *testing.T) {
+	namer := namer.MustNewTimeUnitNamer("test_max_period", ShiftDaily)
+	if _, err := NewFilter(namer, ShiftDaily, 180); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFilter(namer, ShiftDaily, DefaultMaxPeriod+1); err == nil {
+		t.Fatal("period wider than default max period should be rejected")
+	}
+	if _, err := NewFilter(namer, ShiftDaily, DefaultMaxPeriod+1, WithMaxPeriod(DefaultMaxPeriod*2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFilter(namer, ShiftDaily, 10, WithMaxPeriod(5)); err == nil {
+		t.Fatal("period wider than max period should be rejected")
+	}
+	if _, err := NewFilter(namer, ShiftDaily, 0); err == nil {
+		t.Fatal("empty period should be rejected")
+	}
+}
+
 func Disabled_BenchmarkOperationsParallel(b *testing.B) {
 	for _, addr := range testutils.BloomdAddrs() {
 		url := testutils.ParseURL(b, addr)
 		b.Run("Test address "+addr, func(b *testing.B) {
 			c := createClientFromURL(b, url)
 			cp := createClientPoolFromURL(b, url)
-			periods := []clock.UnitNum{1, 5, 10}
+			periods := []clock.UnitNum{1, 5, 10, 50, 180}
 			for _, period := range periods {
 				b.Run(fmt.Sprintf("MultiCheck-p%d", period), func(b *testing.B) {
 					rf := createBenchFilter(b, c, fmt.Sprintf("bench_operations_multicheck_%d_%s", period, url.Scheme), period, ShiftWeekly)
@@ -264,7 +412,7 @@ func BenchmarkOperations(b *testing.B) {
 		url := testutils.ParseURL(b, addr)
 		b.Run("Test address "+addr, func(b *testing.B) {
 			c := createClientFromURL(b, url)
-			periods := []clock.UnitNum{1, 5, 10}
+			periods := []clock.UnitNum{1, 5, 10, 50, 180}
 			ks := generateSeqKeyReaderReseter(100)
 			readResults := make([]bool, 100)
 			for _, period := range periods {
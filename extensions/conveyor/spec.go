@@ -0,0 +1,53 @@
+package conveyor
+
+import (
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/aggregation"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// WithFilterSpec makes writes create filters of the period with spec if it does not exist and retry once
+// filters created by the missing filter policy use spec as well
+func WithFilterSpec(spec ondemand.FilterSpec) Option {
+	return func(rf *Filter) {
+		rf.spec = &spec
+	}
+}
+
+// createOnce creates filter of the unit unless it was already created on demand by this filter
+func (rf *Filter) createOnce(cli *bloomd.Client, unit clock.UnitNum) error {
+	var spec ondemand.FilterSpec
+	if rf.spec != nil {
+		spec = *rf.spec
+	}
+	// units out of the period will not be created again
+	return rf.creator.CreateOnce(cli, unit, rf.nameForUnit(unit), spec, rf.currUnit()-rf.period)
+}
+
+// retryable wraps results of the filter of unit so that writes can be retried if it is missing
+// units of missing filters are appended to missing
+func (rf *Filter) retryable(reader bloomd.ResultReader, unit clock.UnitNum, missing *[]clock.UnitNum) bloomd.ResultReader {
+	if rf.spec == nil {
+		return reader
+	}
+	return aggregation.MissingAsEmpty(reader, func() {
+		*missing = append(*missing, unit)
+	})
+}
+
+// retryMissing creates filters of missing units and pipelines op to them once again
+func (rf *Filter) retryMissing(cli *bloomd.Client, missing []clock.UnitNum, op func(p *bloomd.Pipeline, name string), handle func(unit clock.UnitNum, reader bloomd.ResultReader) error) error {
+	for _, unit := range missing {
+		if err := rf.createOnce(cli, unit); err != nil {
+			return err
+		}
+	}
+	pipeline := cli.Pipeline()
+	for _, unit := range missing {
+		op(pipeline, rf.nameForUnit(unit))
+	}
+	return pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
+		return handle(missing[i], reader)
+	})
+}
@@ -35,3 +35,39 @@ func NewArrayReaderReseter(keys ...bloomd.Key) *ArrayReaderReseter {
 		arr: keys,
 	}
 }
+
+// ToReaderReseter returns reader itself if it can be reset, otherwise it reads all keys into a new ArrayReaderReseter
+// keys are copied, so reader is free to reuse its buffers
+func ToReaderReseter(reader bloomd.KeyReader) KeyReaderReseter {
+	if rr, ok := reader.(KeyReaderReseter); ok {
+		return rr
+	}
+	var keys []bloomd.Key
+	for reader.Next() {
+		keys = append(keys, append(bloomd.Key(nil), reader.Current()...))
+	}
+	return NewArrayReaderReseter(keys...)
+}
+
+// Replay returns a reader of all keys of rr, rr is reset on the first call of Next
+// it allows to pass the same keys to several commands which are written later, e.g. in a pipeline
+func Replay(rr KeyReaderReseter) bloomd.KeyReader {
+	return &replayReader{rr: rr}
+}
+
+type replayReader struct {
+	rr      KeyReaderReseter
+	started bool
+}
+
+func (r *replayReader) Next() bool {
+	if !r.started {
+		r.started = true
+		r.rr.Reset()
+	}
+	return r.rr.Next()
+}
+
+func (r *replayReader) Current() bloomd.Key {
+	return r.rr.Current()
+}
@@ -2,6 +2,7 @@ package namer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/Applifier/go-bloomd/utils/clock"
 )
@@ -31,6 +32,21 @@ func TestTimeUnitNamer(t *testing.T) {
 						unitNum:        14,
 						expectedResult: "test-d14",
 					},
+					{
+						unit:           clock.HourUnit,
+						unitNum:        350,
+						expectedResult: "test-h350",
+					},
+					{
+						unit:           clock.MinuteUnit,
+						unitNum:        21000,
+						expectedResult: "test-min21000",
+					},
+					{
+						unit:           clock.DurationUnit(4 * time.Hour),
+						unitNum:        90,
+						expectedResult: "test-4h90",
+					},
 				}
 				for _, ts := range tests {
 					namer, err := NewTimeUnitNamer(prefix, ts.unit)
@@ -69,6 +85,21 @@ func TestTimeUnitNamer(t *testing.T) {
 					inputString:     "test-d14",
 					expectedUnitNum: 14,
 				},
+				{
+					unit:            clock.HourUnit,
+					inputString:     "test-h350",
+					expectedUnitNum: 350,
+				},
+				{
+					unit:            clock.MinuteUnit,
+					inputString:     "test-min21000",
+					expectedUnitNum: 21000,
+				},
+				{
+					unit:            clock.DurationUnitFrom(4*time.Hour, time.Unix(3600, 0)),
+					inputString:     "test-4h_3600s90",
+					expectedUnitNum: 90,
+				},
 			}
 			for _, ts := range tests {
 				namer, err := NewTimeUnitNamer(prefix, ts.unit)
@@ -108,5 +139,15 @@ func TestTimeUnitNamer(t *testing.T) {
 				}
 			}
 		})
+
+		t.Run("month namer should not parse names of minute filters", func(t *testing.T) {
+			namer, err := NewTimeUnitNamer(prefix, clock.MonthUnit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = namer.ParseUnit("test-min5"); err == nil {
+				t.Fatal("ParseUnit is expected to return error")
+			}
+		})
 	})
 }
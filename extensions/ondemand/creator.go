@@ -0,0 +1,70 @@
+// Package ondemand creates filters of rolling and conveyor extensions once they are found to be missing
+package ondemand
+
+import (
+	"sync"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// FilterSpec describes filters created on demand
+type FilterSpec struct {
+	Capacity int
+	Prob     float64
+	InMemory bool
+}
+
+// Creator creates a filter of every unit once
+// concurrent calls for a unit share a single create command and do not block calls for other units
+// the zero value is ready to use
+type Creator struct {
+	lock     sync.Mutex
+	created  map[clock.UnitNum]bool
+	inflight map[clock.UnitNum]*creation
+}
+
+type creation struct {
+	done chan struct{}
+	err  error
+}
+
+// CreateOnce creates filter with name for the unit using spec unless the creator already created it
+// units up to minUnit are forgotten as their filters are not created anymore
+func (c *Creator) CreateOnce(cli *bloomd.Client, unit clock.UnitNum, name string, spec FilterSpec, minUnit clock.UnitNum) error {
+	c.lock.Lock()
+	if c.created[unit] {
+		c.lock.Unlock()
+		return nil
+	}
+	if cr, ok := c.inflight[unit]; ok {
+		c.lock.Unlock()
+		<-cr.done
+		return cr.err
+	}
+	if c.inflight == nil {
+		c.inflight = make(map[clock.UnitNum]*creation)
+	}
+	cr := &creation{done: make(chan struct{})}
+	c.inflight[unit] = cr
+	c.lock.Unlock()
+
+	_, cr.err = cli.CreateFilter(name, spec.Capacity, spec.Prob, spec.InMemory)
+
+	c.lock.Lock()
+	delete(c.inflight, unit)
+	if cr.err == nil {
+		if c.created == nil {
+			c.created = make(map[clock.UnitNum]bool)
+		}
+		for u := range c.created {
+			if u <= minUnit {
+				delete(c.created, u)
+			}
+		}
+		c.created[unit] = true
+	}
+	c.lock.Unlock()
+	close(cr.done)
+	return cr.err
+}
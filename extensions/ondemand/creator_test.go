@@ -0,0 +1,103 @@
+package ondemand
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/mock"
+)
+
+func TestCreator(t *testing.T) {
+	server, err := mock.NewListener("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+	pool, err := bloomd.NewPoolFromAddr(4, 4, server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	server.SetFaultPlan(mock.NewFaultPlan(1).Add(mock.Fault{Command: "create", Filter: "slow", Latency: 200 * time.Millisecond}))
+
+	var (
+		c  Creator
+		wg sync.WaitGroup
+	)
+	createSlow := func() {
+		defer wg.Done()
+		cli, err := pool.Get()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer cli.Close()
+		if err := c.CreateOnce(cli, 1, "slow", FilterSpec{InMemory: true}, 0); err != nil {
+			t.Error(err)
+		}
+	}
+
+	wg.Add(2)
+	go createSlow()
+	go createSlow()
+	time.Sleep(20 * time.Millisecond)
+
+	t.Run("other units should not wait for a slow create", func(t *testing.T) {
+		cli, err := pool.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cli.Close()
+		started := time.Now()
+		if err := c.CreateOnce(cli, 2, "fast", FilterSpec{InMemory: true}, 0); err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(started); elapsed > 100*time.Millisecond {
+			t.Errorf("create of other unit took %s", elapsed)
+		}
+	})
+
+	t.Run("unit should be created once", func(t *testing.T) {
+		wg.Wait()
+		cli, err := pool.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cli.Close()
+		if err := c.CreateOnce(cli, 1, "slow", FilterSpec{InMemory: true}, 0); err != nil {
+			t.Fatal(err)
+		}
+		creates := 0
+		for _, cmd := range server.Commands() {
+			if cmd.Name == "create" && cmd.Filter == "slow" {
+				creates++
+			}
+		}
+		if creates != 1 {
+			t.Errorf("single create command expected but got %d", creates)
+		}
+	})
+
+	t.Run("units out of period should be forgotten", func(t *testing.T) {
+		cli, err := pool.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cli.Close()
+		if err := c.CreateOnce(cli, 3, "fast", FilterSpec{InMemory: true}, 2); err != nil {
+			t.Fatal(err)
+		}
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		if c.created[1] || c.created[2] || !c.created[3] {
+			t.Errorf("only unit 3 expected to be remembered but got %v", c.created)
+		}
+	})
+}
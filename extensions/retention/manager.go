@@ -0,0 +1,254 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// ErrInvalidInterval is returned if manager interval is not positive
+var ErrInvalidInterval = errors.New("interval should be positive")
+
+// Filter is a filter rolling through time, e.g. *rolling.Filter or *conveyor.Filter
+// manager computes names of filters of a target from its units
+type Filter interface {
+	CurrentUnit() clock.UnitNum
+	Period() clock.UnitNum
+	NameFor(unit clock.UnitNum) string
+	ParseUnit(name string) (clock.UnitNum, error)
+}
+
+// Target describes retention of a filter maintained by Manager
+type Target struct {
+	// Name identifies the target in reported actions and errors
+	Name   string
+	Filter Filter
+	// Advance is amount of upcoming units to create filters for
+	Advance clock.UnitNum
+	// Tail is amount of units older than period to keep
+	Tail clock.UnitNum
+	// Capacity, Prob and InMemory are used to create filters
+	Capacity int
+	Prob     float64
+	InMemory bool
+}
+
+// ActionKind is a kind of action performed by Manager
+type ActionKind string
+
+const (
+	// ActionCreate is reported for every created filter
+	ActionCreate = ActionKind("create")
+	// ActionDrop is reported for every dropped filter
+	ActionDrop = ActionKind("drop")
+	// ActionClose is reported for every closed idle filter
+	ActionClose = ActionKind("close")
+)
+
+// Action describes an action performed by Manager on a filter
+type Action struct {
+	Time       time.Time
+	Target     string
+	Kind       ActionKind
+	FilterName string
+}
+
+// Manager periodically creates upcoming filters of targets, drops expired ones and closes idle past ones
+type Manager struct {
+	pool        *bloomd.Pool
+	interval    time.Duration
+	targets     []Target
+	clock       clock.Clock
+	idleTimeout time.Duration
+	onAction    func(action Action)
+	onError     func(target string, err error)
+	// activities of past filters by name, they are used to find idle filters
+	activities map[string]activity
+	lock       sync.Mutex
+}
+
+type activity struct {
+	ops    uint64
+	since  time.Time
+	closed bool
+}
+
+// Option configures a Manager
+type Option func(m *Manager)
+
+// WithClock makes manager take current time from clk instead of the global clock
+// filters of targets use their own clocks to compute units
+func WithClock(clk clock.Clock) Option {
+	return func(m *Manager) {
+		m.clock = clk
+	}
+}
+
+// WithIdleTimeout makes manager close past filters which had neither checks nor sets for idleTimeout
+// closed filters are paged in by bloomd on the next access, idle filters are not closed unless the option is used
+func WithIdleTimeout(idleTimeout time.Duration) Option {
+	return func(m *Manager) {
+		m.idleTimeout = idleTimeout
+	}
+}
+
+// NewManager creates a manager maintaining targets through clients of the pool every interval
+func NewManager(pool *bloomd.Pool, interval time.Duration, targets []Target, opts ...Option) (*Manager, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	m := &Manager{
+		pool:       pool,
+		interval:   interval,
+		targets:    targets,
+		clock:      clock.Global,
+		activities: make(map[string]activity),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// OnAction sets a callback for performed actions
+// it should be called before the manager is used
+func (m *Manager) OnAction(fn func(action Action)) {
+	m.onAction = fn
+}
+
+// OnError sets a callback for errors, an error of a target does not stop maintenance of other targets
+// it should be called before the manager is used
+func (m *Manager) OnError(fn func(target string, err error)) {
+	m.onError = fn
+}
+
+// Run maintains targets every interval until ctx is done
+func (m *Manager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick maintains all targets once
+func (m *Manager) Tick(ctx context.Context) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	cli, err := m.pool.GetContext(ctx)
+	if err != nil {
+		m.reportError("", err)
+		return
+	}
+	defer cli.Close()
+	stats, err := cli.ListFilterStats()
+	if err != nil {
+		m.reportError("", err)
+		return
+	}
+	seen := make(map[string]bool)
+	for _, target := range m.targets {
+		if err := m.maintain(ctx, cli, target, stats, seen); err != nil {
+			m.reportError(target.Name, err)
+		}
+	}
+	// forget filters which do not exist anymore
+	for name := range m.activities {
+		if !seen[name] {
+			delete(m.activities, name)
+		}
+	}
+}
+
+// maintain creates missing filters of target through advance, drops the ones older than period and tail
+// and closes idle past ones, filters are picked from the listed ones by names of target units
+func (m *Manager) maintain(ctx context.Context, cli *bloomd.Client, target Target, stats []bloomd.FilterStats, seen map[string]bool) error {
+	plan := planFor(stats, target.Filter, target.Advance, target.Tail)
+	for _, pf := range plan.Drop {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := cli.GetFilter(pf.Name).Drop(); err != nil {
+			if err == bloomd.ErrFilterNotExist {
+				continue
+			}
+			return err
+		}
+		m.report(Action{Target: target.Name, Kind: ActionDrop, FilterName: pf.Name})
+	}
+	if m.idleTimeout > 0 {
+		currUnit := target.Filter.CurrentUnit()
+		for _, pf := range plan.Keep {
+			if pf.Unit >= currUnit {
+				break
+			}
+			seen[pf.Name] = true
+			if err := m.closeIfIdle(cli.GetFilter(pf.Name), target.Name); err != nil {
+				return err
+			}
+		}
+	}
+	for _, pf := range plan.Create {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := cli.CreateFilter(pf.Name, target.Capacity, target.Prob, target.InMemory); err != nil {
+			return err
+		}
+		m.report(Action{Target: target.Name, Kind: ActionCreate, FilterName: pf.Name})
+	}
+	return nil
+}
+
+// closeIfIdle closes filter if its checks and sets did not change for idle timeout
+func (m *Manager) closeIfIdle(f bloomd.Filter, target string) error {
+	info, err := f.Info()
+	if err != nil {
+		return err
+	}
+	ops := parseCounter(info["checks"]) + parseCounter(info["sets"])
+	now := m.clock.Now()
+	act, ok := m.activities[f.Name]
+	if !ok || act.ops != ops {
+		m.activities[f.Name] = activity{ops: ops, since: now}
+		return nil
+	}
+	if act.closed || now.Sub(act.since) < m.idleTimeout {
+		return nil
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	act.closed = true
+	m.activities[f.Name] = act
+	m.report(Action{Target: target, Kind: ActionClose, FilterName: f.Name})
+	return nil
+}
+
+func (m *Manager) report(action Action) {
+	if m.onAction != nil {
+		action.Time = m.clock.Now()
+		m.onAction(action)
+	}
+}
+
+func (m *Manager) reportError(target string, err error) {
+	if m.onError != nil {
+		m.onError(target, err)
+	}
+}
+
+func parseCounter(s string) uint64 {
+	n, _ := strconv.ParseUint(s, 10, 64)
+	return n
+}
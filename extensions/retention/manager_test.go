@@ -0,0 +1,166 @@
+package retention
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/conveyor"
+	"github.com/Applifier/go-bloomd/extensions/namer"
+	"github.com/Applifier/go-bloomd/extensions/rolling"
+	"github.com/Applifier/go-bloomd/utils/clock"
+	"github.com/Applifier/go-bloomd/utils/period"
+	"github.com/Applifier/go-bloomd/utils/testutils"
+)
+
+var (
+	_ Filter = (*rolling.Filter)(nil)
+	_ Filter = (*conveyor.Filter)(nil)
+)
+
+var manageOperationTimeout = 1 * time.Second
+
+func TestManager(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		pool, err := bloomd.NewPoolFromURL(1, 10, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pool.Close()
+		c, err := pool.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		now := time.Date(2018, 2, 14, 12, 0, 0, 0, time.UTC)
+		day := clock.DayNumOf(now)
+		clk := clock.NewManualClock(now)
+		nr := namer.MustNewTimeUnitNamer("test_retention_"+url.Scheme, rolling.RollDaily)
+		rf, err := rolling.NewFilter(nr, rolling.RollDaily, 3, rolling.WithClock(clk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dropFilters(t, c, nr)
+		defer dropFilters(t, c, nr)
+		broken, err := rolling.NewFilter(namer.MustNewTimeUnitNamer("test_retention_broken_"+url.Scheme, rolling.RollDaily), rolling.RollDaily, 3, rolling.WithClock(clk))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			actions []Action
+			errs    []string
+		)
+		m, err := NewManager(pool, time.Hour, []Target{
+			// filters can not be created with probability but without capacity
+			{Name: "broken", Filter: broken, Prob: 0.01},
+			{Name: "rolling", Filter: rf, Advance: 1, InMemory: true},
+		}, WithClock(clk), WithIdleTimeout(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.OnAction(func(action Action) {
+			actions = append(actions, action)
+		})
+		m.OnError(func(target string, err error) {
+			errs = append(errs, target)
+		})
+		tick := func() {
+			ctx, cancel := getContext(manageOperationTimeout)
+			defer cancel()
+			m.Tick(ctx)
+		}
+		expectActions := func(t *testing.T, kind ActionKind, units ...clock.UnitNum) {
+			t.Helper()
+			var names, expected []string
+			for _, action := range actions {
+				if action.Target != "rolling" || !action.Time.Equal(clk.Now()) {
+					t.Errorf("unexpected action %+v", action)
+				}
+				if action.Kind == kind {
+					names = append(names, action.FilterName)
+				}
+			}
+			for _, unit := range units {
+				expected = append(expected, nr.NameFor(unit))
+			}
+			sort.Strings(names)
+			sort.Strings(expected)
+			if len(names) != len(expected) {
+				t.Fatalf("%s of %v expected but got %v", kind, expected, names)
+			}
+			for i := range names {
+				if names[i] != expected[i] {
+					t.Fatalf("%s of %v expected but got %v", kind, expected, names)
+				}
+			}
+		}
+
+		t.Run("create upcoming filters", func(t *testing.T) {
+			tick()
+			if len(errs) != 1 || errs[0] != "broken" {
+				t.Errorf("error of broken target expected but got %v", errs)
+			}
+			expectActions(t, ActionCreate, day-2, day-1, day, day+1)
+			actions = nil
+			tick()
+			if len(actions) != 0 {
+				t.Errorf("no actions expected for existing filters but got %+v", actions)
+			}
+		})
+
+		t.Run("close idle filters", func(t *testing.T) {
+			clk.Advance(2 * time.Hour)
+			if _, err := c.GetFilter(nr.NameFor(day - 1)).Check(bloomd.Key("foo")); err != nil {
+				t.Fatal(err)
+			}
+			tick()
+			expectActions(t, ActionClose, day-2)
+			actions = nil
+			tick()
+			if len(actions) != 0 {
+				t.Errorf("closed filter should not be closed again but got %+v", actions)
+			}
+		})
+
+		t.Run("roll filters", func(t *testing.T) {
+			clk.Advance(period.Day)
+			tick()
+			expectActions(t, ActionCreate, day+2)
+			expectActions(t, ActionDrop, day-2)
+		})
+	})
+}
+
+func TestNewManagerInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if _, err := NewManager(nil, interval, nil); err != ErrInvalidInterval {
+			t.Errorf("ErrInvalidInterval expected for interval %v but got %v", interval, err)
+		}
+	}
+}
+
+// dropFilters drops every filter named by nr, including the ones outside of the period
+func dropFilters(t *testing.T, c *bloomd.Client, nr rolling.Namer) {
+	t.Helper()
+	filters, err := c.ListFilters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range filters {
+		if unit, err := nr.ParseUnit(f.Name); err != nil || nr.NameFor(unit) != f.Name {
+			continue
+		}
+		if err := c.GetFilter(f.Name).Drop(); err != nil && err != bloomd.ErrFilterNotExist {
+			t.Error(err)
+		}
+	}
+}
+
+func getContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), timeout)
+}
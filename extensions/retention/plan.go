@@ -0,0 +1,94 @@
+package retention
+
+import (
+	"context"
+	"sort"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// PlannedFilter is a filter of a retention plan
+type PlannedFilter struct {
+	Name string
+	Unit clock.UnitNum
+	// Stats are reported by the list command, they are empty for filters to be created
+	Stats bloomd.FilterStats
+}
+
+// Plan lists filters to be created, kept and dropped, filters are ordered by units
+type Plan struct {
+	Create []PlannedFilter
+	Keep   []PlannedFilter
+	Drop   []PlannedFilter
+}
+
+// PlanRetention plans creation of missing filters of f through its period and dropping of filters older than period and tail
+// the plan is what Manager would do for a target of f without advance, so it can be reviewed before it is executed
+func PlanRetention(cli *bloomd.Client, f Filter, tail clock.UnitNum) (Plan, error) {
+	stats, err := cli.ListFilterStats()
+	if err != nil {
+		return Plan{}, err
+	}
+	return planFor(stats, f, 0, tail), nil
+}
+
+// ExecutePlan creates and drops filters of a previously reviewed plan, filters to be created use spec
+// filters to be dropped which do not exist anymore are considered dropped
+func ExecutePlan(ctx context.Context, cli *bloomd.Client, plan Plan, spec ondemand.FilterSpec) error {
+	for _, pf := range plan.Create {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := cli.CreateFilter(pf.Name, spec.Capacity, spec.Prob, spec.InMemory); err != nil {
+			return err
+		}
+	}
+	for _, pf := range plan.Drop {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := cli.GetFilter(pf.Name).Drop(); err != nil && err != bloomd.ErrFilterNotExist {
+			return err
+		}
+	}
+	return nil
+}
+
+// planFor picks filters of f from listed ones by names of its units
+// filters are created from the oldest unit of the period through advance and dropped up to tail units before the period
+func planFor(stats []bloomd.FilterStats, f Filter, advance clock.UnitNum, tail clock.UnitNum) Plan {
+	currUnit := f.CurrentUnit()
+	minUnit := currUnit - f.Period() - tail
+	var plan Plan
+	existing := make(map[clock.UnitNum]bool)
+	for _, s := range stats {
+		unit, err := f.ParseUnit(s.Name)
+		// skip filters which are not named by f, e.g. filters of other namers with a colliding prefix
+		if err != nil || f.NameFor(unit) != s.Name {
+			continue
+		}
+		existing[unit] = true
+		pf := PlannedFilter{Name: s.Name, Unit: unit, Stats: s}
+		if unit <= minUnit {
+			plan.Drop = append(plan.Drop, pf)
+		} else {
+			plan.Keep = append(plan.Keep, pf)
+		}
+	}
+	for unit := currUnit - f.Period() + 1; unit <= currUnit+advance; unit++ {
+		if !existing[unit] {
+			plan.Create = append(plan.Create, PlannedFilter{Name: f.NameFor(unit), Unit: unit})
+		}
+	}
+	sortByUnit(plan.Keep)
+	sortByUnit(plan.Drop)
+	return plan
+}
+
+func sortByUnit(filters []PlannedFilter) {
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].Unit < filters[j].Unit
+	})
+}
@@ -0,0 +1,95 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/namer"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
+	"github.com/Applifier/go-bloomd/extensions/rolling"
+	"github.com/Applifier/go-bloomd/utils/clock"
+	"github.com/Applifier/go-bloomd/utils/testutils"
+)
+
+func TestPlanRetention(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c, err := bloomd.NewFromURL(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+
+		now := time.Date(2018, 2, 14, 12, 0, 0, 0, time.UTC)
+		day := clock.DayNumOf(now)
+		nr := namer.MustNewTimeUnitNamer("test_retention_plan_"+url.Scheme, rolling.RollDaily)
+		rf, err := rolling.NewFilter(nr, rolling.RollDaily, 3, rolling.WithClock(clock.NewManualClock(now)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dropFilters(t, c, nr)
+		defer dropFilters(t, c, nr)
+		// the name parses to a unit of the filter but is not named by it
+		colliding := fmt.Sprintf("test_retention_plan_%s-d0%d", url.Scheme, day-5)
+		defer c.GetFilter(colliding).Drop()
+		for _, name := range []string{nr.NameFor(day - 5), nr.NameFor(day - 4), nr.NameFor(day - 3), nr.NameFor(day), colliding} {
+			if _, err := c.CreateFilter(name, 0, 0, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expectUnits := func(t *testing.T, kind string, filters []PlannedFilter, units ...clock.UnitNum) {
+			t.Helper()
+			if len(filters) != len(units) {
+				t.Fatalf("%s of %v expected but got %+v", kind, units, filters)
+			}
+			for i, pf := range filters {
+				if pf.Unit != units[i] || pf.Name != nr.NameFor(units[i]) {
+					t.Fatalf("%s of %v expected but got %+v", kind, units, filters)
+				}
+			}
+		}
+
+		plan, err := PlanRetention(c, rf, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectUnits(t, "create", plan.Create, day-2, day-1)
+		expectUnits(t, "keep", plan.Keep, day-3, day)
+		expectUnits(t, "drop", plan.Drop, day-5, day-4)
+
+		t.Run("canceled execution should not change filters", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if err := ExecutePlan(ctx, c, plan, ondemand.FilterSpec{InMemory: true}); err != context.Canceled {
+				t.Fatalf("context.Canceled expected but got %v", err)
+			}
+			again, err := PlanRetention(c, rf, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectUnits(t, "create", again.Create, day-2, day-1)
+			expectUnits(t, "drop", again.Drop, day-5, day-4)
+		})
+
+		t.Run("executed plan should leave nothing to do", func(t *testing.T) {
+			ctx, cancel := getContext(manageOperationTimeout)
+			defer cancel()
+			if err := ExecutePlan(ctx, c, plan, ondemand.FilterSpec{InMemory: true}); err != nil {
+				t.Fatal(err)
+			}
+			again, err := PlanRetention(c, rf, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectUnits(t, "create", again.Create)
+			expectUnits(t, "keep", again.Keep, day-3, day-2, day-1, day)
+			expectUnits(t, "drop", again.Drop)
+			if _, err := c.GetFilter(colliding).Info(); err != nil {
+				t.Errorf("filter %s of another namer should not be dropped but got %v", colliding, err)
+			}
+		})
+	})
+}
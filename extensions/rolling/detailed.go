@@ -0,0 +1,174 @@
+package rolling
+
+import (
+	"context"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/aggregation"
+	"github.com/Applifier/go-bloomd/extensions/input"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// UnitMask is a bitmask of units of the period, bit i is set for the unit i units before the current one
+type UnitMask []uint64
+
+func newUnitMask(period clock.UnitNum) UnitMask {
+	return make(UnitMask, (period+63)/64)
+}
+
+// Has returns true if bit of the unit i units before the current one is set
+func (m UnitMask) Has(i clock.UnitNum) bool {
+	if int(i/64) >= len(m) {
+		return false
+	}
+	return m[i/64]&(1<<(i%64)) != 0
+}
+
+// Count returns amount of set bits
+func (m UnitMask) Count() int {
+	count := 0
+	for _, word := range m {
+		for ; word != 0; word &= word - 1 {
+			count++
+		}
+	}
+	return count
+}
+
+func (m UnitMask) set(i clock.UnitNum) {
+	m[i/64] |= 1 << (i % 64)
+}
+
+// Detail describes in which units of the period a key was found
+type Detail struct {
+	// Found is true if the key was found in any unit of the period
+	Found bool
+	// LastUnit is the most recent unit where the key was found
+	LastUnit clock.UnitNum
+	// From and To is the time range [From, To) of LastUnit
+	From time.Time
+	To   time.Time
+	// Units has bits set for all units of the period where the key was found
+	Units UnitMask
+}
+
+// CheckDetailed checks filters through period and returns all units where the key was found
+// missing filters are treated according to the missing filter policy
+func (rf *Filter) CheckDetailed(ctx context.Context, cli *bloomd.Client, k bloomd.Key) (Detail, error) {
+	details, err := rf.MultiCheckDetailed(ctx, cli, input.NewArrayReaderReseter(k))
+	if err != nil {
+		return Detail{}, err
+	}
+	return details[0], nil
+}
+
+// MultiCheckDetailed checks filters through period and returns all units where each of the keys was found
+// commands to all filters are pipelined, so the whole period costs a single round trip
+// missing filters are treated according to the missing filter policy
+func (rf *Filter) MultiCheckDetailed(ctx context.Context, cli *bloomd.Client, rr input.KeyReaderReseter) ([]Detail, error) {
+	deadline, checkDeadline := ctx.Deadline()
+	if checkDeadline && deadline.Before(time.Now()) {
+		return nil, context.DeadlineExceeded
+	}
+	currUnit := rf.currUnit()
+	pipeline := cli.Pipeline()
+	for i := clock.UnitZero; i < rf.period; i++ {
+		pipeline.MultiCheck(rf.nameForUnit(currUnit-i), input.Replay(rr))
+	}
+	var (
+		details []Detail
+		missing []clock.UnitNum
+	)
+	err := pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
+		if checkDeadline && deadline.Before(time.Now()) {
+			return context.DeadlineExceeded
+		}
+		reader = rf.missingPolicy.Results(reader, currUnit-clock.UnitNum(i), &missing)
+		if i == 0 {
+			details = make([]Detail, reader.Length())
+			for j := range details {
+				details[j].Units = newUnitMask(rf.period)
+			}
+		}
+		for j := range details {
+			found, err := reader.Next()
+			if err != nil {
+				return err
+			}
+			if found {
+				details[j].Units.set(clock.UnitNum(i))
+			}
+		}
+		return nil
+	})
+	rr.Reset()
+	if err == nil {
+		err = rf.missingPolicy.CreateMissing(cli, missing, rf.createOnce)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for j := range details {
+		for i := clock.UnitZero; i < rf.period; i++ {
+			if details[j].Units.Has(i) {
+				if err := rf.setLastUnit(&details[j], currUnit-i); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+	return details, nil
+}
+
+// CountUnits checks filters through period and returns for each of the keys in how many units it was found
+// commands to all filters are pipelined, so the whole period costs a single round trip
+// missing filters are treated according to the missing filter policy
+func (rf *Filter) CountUnits(ctx context.Context, cli *bloomd.Client, rr input.KeyReaderReseter) (*aggregation.CountsSet, error) {
+	deadline, checkDeadline := ctx.Deadline()
+	if checkDeadline && deadline.Before(time.Now()) {
+		return nil, context.DeadlineExceeded
+	}
+	currUnit := rf.currUnit()
+	pipeline := cli.Pipeline()
+	for i := clock.UnitZero; i < rf.period; i++ {
+		pipeline.MultiCheck(rf.nameForUnit(currUnit-i), input.Replay(rr))
+	}
+	var (
+		cs      *aggregation.CountsSet
+		missing []clock.UnitNum
+	)
+	err := pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
+		if checkDeadline && deadline.Before(time.Now()) {
+			return context.DeadlineExceeded
+		}
+		reader = rf.missingPolicy.Results(reader, currUnit-clock.UnitNum(i), &missing)
+		if i == 0 {
+			cs = aggregation.GetCountsSet(reader.Length())
+		}
+		return cs.CountFromReader(reader)
+	})
+	rr.Reset()
+	if err == nil {
+		err = rf.missingPolicy.CreateMissing(cli, missing, rf.createOnce)
+	}
+	if err != nil {
+		if cs != nil {
+			cs.Close()
+		}
+		return nil, err
+	}
+	return cs, nil
+}
+
+func (rf *Filter) setLastUnit(detail *Detail, unit clock.UnitNum) (err error) {
+	detail.Found = true
+	detail.LastUnit = unit
+	if rf.calendar != nil {
+		detail.From, detail.To, err = rf.calendar.RangeOf(rf.unit, unit)
+	} else {
+		detail.From, detail.To, err = clock.RangeOf(rf.unit, unit)
+	}
+	return err
+}
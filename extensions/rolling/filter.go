@@ -8,6 +8,7 @@ import (
 	bloomd "github.com/Applifier/go-bloomd"
 	"github.com/Applifier/go-bloomd/extensions/aggregation"
 	"github.com/Applifier/go-bloomd/extensions/input"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
 	"github.com/Applifier/go-bloomd/utils/clock"
 )
 
@@ -18,6 +19,10 @@ const (
 	RollWeekly = clock.WeekUnit
 	// RollMonthly roll filter every month
 	RollMonthly = clock.MonthUnit
+	// RollHourly roll filter every hour
+	RollHourly = clock.HourUnit
+	// RollMinutely roll filter every minute
+	RollMinutely = clock.MinuteUnit
 )
 
 // Namer maps filter names to units
@@ -32,104 +37,236 @@ type Filter struct {
 	period   clock.UnitNum
 	unit     clock.Unit
 	currUnit func() clock.UnitNum
+	calendar *clock.Calendar
+	clock    clock.Clock
+	// maxPeriod is the widest period accepted by NewFilter
+	maxPeriod clock.UnitNum
+	// missingPolicy defines how checks treat filters that do not exist
+	missingPolicy aggregation.MissingFilterPolicy
+	// spec is a template of filters created on demand
+	spec *ondemand.FilterSpec
+	// creator creates filters on demand once per unit
+	creator ondemand.Creator
 }
 
-var currUnitMap = map[clock.Unit]func() clock.UnitNum{
-	RollDaily:   clock.DayNum,
-	RollMonthly: clock.MonthNum,
-	RollWeekly:  clock.WeekNum,
+// DefaultMaxPeriod is the widest period accepted by NewFilter unless WithMaxPeriod is used
+var DefaultMaxPeriod = clock.UnitNum(400)
+
+// Option configures a Filter
+type Option func(rf *Filter)
+
+// WithClock makes filter take current time from clk instead of the global clock
+func WithClock(clk clock.Clock) Option {
+	return func(rf *Filter) {
+		rf.clock = clk
+	}
+}
+
+// WithMaxPeriod overrides DefaultMaxPeriod for the filter
+func WithMaxPeriod(maxPeriod clock.UnitNum) Option {
+	return func(rf *Filter) {
+		rf.maxPeriod = maxPeriod
+	}
+}
+
+// WithMissingFilterPolicy sets how checks treat missing filters, so windows can start without creating the full history
+func WithMissingFilterPolicy(policy aggregation.MissingFilterPolicy) Option {
+	return func(rf *Filter) {
+		rf.missingPolicy = policy
+	}
+}
+
+// WithCalendar makes filter compute units in the calendar, e.g. to roll filters at local midnight
+func WithCalendar(calendar *clock.Calendar) Option {
+	return func(rf *Filter) {
+		rf.calendar = calendar
+	}
+}
+
+var unitNumOfMap = map[clock.Unit]func(t time.Time) clock.UnitNum{
+	RollDaily:    clock.DayNumOf,
+	RollMonthly:  clock.MonthNumOf,
+	RollWeekly:   clock.WeekNumOf,
+	RollHourly:   clock.HourNumOf,
+	RollMinutely: clock.MinuteNumOf,
 }
 
 // NewFilter creates a new Filter
-// unit - unit of time, e.g. Week, Day or Month. All keys being set during period with a same unit will be stored in a single filter.
-// period - period in specified time units to consider in check operations
+// unit - unit of time, e.g. Month, Week, Day, Hour, Minute or a duration unit created by clock.DurationUnit. All keys being set during period with a same unit will be stored in a single filter.
+// period - period in specified time units to consider in check operations, at least 1
 // namer - provides algorithm to name filters according to specified unit of time
-func NewFilter(namer Namer, unit clock.Unit, period clock.UnitNum) (*Filter, error) {
-	currUnitFunc, ok := currUnitMap[unit]
-	if !ok {
-		return nil, fmt.Errorf("Unit %s does not supported", unit)
-	}
-	if period >= 100 { // TODO there should be tests to find a good max period
-		return nil, fmt.Errorf("Too wide period")
-	}
-	return &Filter{
-		namer:    namer,
-		unit:     unit,
-		period:   period,
-		currUnit: currUnitFunc,
-	}, nil
+// opts - optional settings of the filter
+func NewFilter(namer Namer, unit clock.Unit, period clock.UnitNum, opts ...Option) (*Filter, error) {
+	rf := &Filter{
+		namer:     namer,
+		unit:      unit,
+		period:    period,
+		clock:     clock.Global,
+		maxPeriod: DefaultMaxPeriod,
+	}
+	for _, opt := range opts {
+		opt(rf)
+	}
+	if period < 1 {
+		return nil, fmt.Errorf("Too narrow period %d, period should be at least 1", period)
+	}
+	if period > rf.maxPeriod {
+		return nil, fmt.Errorf("Too wide period %d, max period is %d", period, rf.maxPeriod)
+	}
+	unitNumOf, err := rf.unitNumOfFunc()
+	if err != nil {
+		return nil, err
+	}
+	rf.currUnit = func() clock.UnitNum {
+		return unitNumOf(rf.clock.Now())
+	}
+	return rf, nil
+}
+
+func (rf *Filter) unitNumOfFunc() (func(t time.Time) clock.UnitNum, error) {
+	if rf.calendar != nil {
+		unitNumOf, err := rf.calendar.NumOfFunc(rf.unit)
+		if err != nil {
+			return nil, fmt.Errorf("Unit %s does not supported", rf.unit)
+		}
+		return unitNumOf, nil
+	}
+	if unitNumOf, ok := unitNumOfMap[rf.unit]; ok {
+		return unitNumOf, nil
+	}
+	buckets, err := clock.ParseDurationUnit(rf.unit)
+	if err != nil {
+		return nil, fmt.Errorf("Unit %s does not supported", rf.unit)
+	}
+	return buckets.NumOf, nil
 }
 
 // BulkSet sets keys to filter that corresponds to a lates unit
-// note that it does not check if filter exists
+// if filter spec is set a missing filter is created and keys are set once again,
+// so keys are read into memory if reader can not be reset and results are read into memory as well
 func (rf *Filter) BulkSet(ctx context.Context, cli *bloomd.Client, reader bloomd.KeyReader) (bloomd.ResultReader, error) {
 	currUnit := rf.currUnit()
 	f := cli.GetFilter(rf.nameForUnit(currUnit))
-	return f.BulkSet(reader)
+	if rf.spec == nil {
+		return f.BulkSet(reader)
+	}
+	rr := input.ToReaderReseter(reader)
+	rs, err := bulkSetInMemory(f, rr)
+	if err == bloomd.ErrFilterNotExist {
+		if err = rf.createOnce(cli, currUnit); err != nil {
+			return nil, err
+		}
+		rr.Reset()
+		rs, err = bulkSetInMemory(f, rr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func bulkSetInMemory(f bloomd.Filter, reader bloomd.KeyReader) (*aggregation.ResultsSet, error) {
+	results, err := f.BulkSet(reader)
+	if err != nil {
+		return nil, err
+	}
+	rs := aggregation.GetResultSet(results.Length())
+	if err := rs.FillFromReader(results); err != nil {
+		rs.Close()
+		return nil, err
+	}
+	return rs, nil
 }
 
-// MultiCheck sequentially checks filters through period
-// note that it does not check if filters exist
+// MultiCheck checks filters through period
+// commands to all filters are pipelined, so the whole period costs a single round trip
+// missing filters are treated according to the missing filter policy
 func (rf *Filter) MultiCheck(ctx context.Context, cli *bloomd.Client, rr input.KeyReaderReseter) (resultReader bloomd.ResultReader, err error) {
 	deadline, checkDeadline := ctx.Deadline()
+	if checkDeadline && deadline.Before(time.Now()) {
+		return nil, context.DeadlineExceeded
+	}
 	currUnit := rf.currUnit()
-	var rs *aggregation.ResultsSet
-	defer func() {
-		if err != nil && rs != nil {
-			rs.Close()
-		}
-	}()
+	pipeline := cli.Pipeline()
 	for i := clock.UnitZero; i < rf.period; i++ {
+		pipeline.MultiCheck(rf.nameForUnit(currUnit-i), input.Replay(rr))
+	}
+	var (
+		rs      *aggregation.ResultsSet
+		missing []clock.UnitNum
+	)
+	err = pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
 		if checkDeadline && deadline.Before(time.Now()) {
-			return nil, context.DeadlineExceeded
-		}
-		f := cli.GetFilter(rf.nameForUnit(currUnit - i))
-		reader, err := f.MultiCheck(rr)
-		if err != nil {
-			return nil, err
+			return context.DeadlineExceeded
 		}
+		reader = rf.missingPolicy.Results(reader, currUnit-clock.UnitNum(i), &missing)
 		if i == 0 {
 			rs = aggregation.GetResultSet(reader.Length())
-			if err = rs.FillFromReader(reader); err != nil {
-				return nil, err
-			}
-		} else {
-			if err = rs.MergeFromReader(reader); err != nil {
-				return nil, err
-			}
+			return rs.FillFromReader(reader)
 		}
-		rr.Reset()
+		return rs.MergeFromReader(reader)
+	})
+	rr.Reset()
+	if err == nil {
+		err = rf.missingPolicy.CreateMissing(cli, missing, rf.createOnce)
+	}
+	if err != nil {
+		if rs != nil {
+			rs.Close()
+		}
+		return nil, err
 	}
 	return rs, nil
 }
 
 // Set sets key to filter that corresponds to a lates unit
-// note that it does not check if filter exists
+// if filter spec is set a missing filter is created and key is set once again
 func (rf *Filter) Set(ctx context.Context, cli *bloomd.Client, k bloomd.Key) (bool, error) {
 	currUnit := rf.currUnit()
 	f := cli.GetFilter(rf.nameForUnit(currUnit))
-	return f.Set(k)
+	result, err := f.Set(k)
+	if err == bloomd.ErrFilterNotExist && rf.spec != nil {
+		if err = rf.createOnce(cli, currUnit); err != nil {
+			return false, err
+		}
+		return f.Set(k)
+	}
+	return result, err
 }
 
-// Check sequentially checks filters through period
-// note that it does not check if filters exist
+// Check checks filters through period
+// commands to all filters are written at once and answers are read afterwards, so the whole period costs a single round trip
+// the rest of answers is drained as soon as the key is found
+// missing filters are treated according to the missing filter policy
 func (rf *Filter) Check(ctx context.Context, cli *bloomd.Client, k bloomd.Key) (bool, error) {
 	deadline, checkDeadline := ctx.Deadline()
+	if checkDeadline && deadline.Before(time.Now()) {
+		return false, context.DeadlineExceeded
+	}
 	currUnit := rf.currUnit()
+	pipeline := cli.Pipeline()
 	for i := clock.UnitZero; i < rf.period; i++ {
-		if checkDeadline && deadline.Before(time.Now()) {
-			return false, context.DeadlineExceeded
-		}
-		f := cli.GetFilter(rf.nameForUnit(currUnit - i))
-		val, err := f.Check(k)
-		if err != nil {
-			return false, err
-		}
-		if val {
-			return true, nil
+		pipeline.Check(rf.nameForUnit(currUnit-i), k)
+	}
+	var (
+		found   bool
+		missing []clock.UnitNum
+	)
+	err := pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
+		if found {
+			return nil
 		}
+		val, err := rf.missingPolicy.Results(reader, currUnit-clock.UnitNum(i), &missing).Next()
+		found = val
+		return err
+	})
+	if err == nil {
+		err = rf.missingPolicy.CreateMissing(cli, missing, rf.createOnce)
 	}
-	return false, nil
+	if err != nil {
+		return false, err
+	}
+	return found, nil
 }
 
 // Drop drops all filters through period
@@ -254,6 +391,26 @@ type unitFilter struct {
 	unit   clock.UnitNum
 }
 
+// CurrentUnit returns the unit of the current time
+func (rf *Filter) CurrentUnit() clock.UnitNum {
+	return rf.currUnit()
+}
+
+// Period returns amount of units considered by the filter
+func (rf *Filter) Period() clock.UnitNum {
+	return rf.period
+}
+
+// NameFor returns name of the filter of the unit
+func (rf *Filter) NameFor(unit clock.UnitNum) string {
+	return rf.nameForUnit(unit)
+}
+
+// ParseUnit returns unit of the filter name, it fails if the name does not belong to the filter
+func (rf *Filter) ParseUnit(name string) (clock.UnitNum, error) {
+	return rf.namer.ParseUnit(name)
+}
+
 func (rf *Filter) nameForUnit(unit clock.UnitNum) string {
 	return rf.namer.NameFor(unit)
 }
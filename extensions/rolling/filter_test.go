@@ -7,8 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Applifier/go-bloomd/extensions/aggregation"
 	"github.com/Applifier/go-bloomd/extensions/input"
 	"github.com/Applifier/go-bloomd/extensions/namer"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
+	"github.com/Applifier/go-bloomd/mock"
 	"github.com/Applifier/go-bloomd/utils/period"
 
 	"github.com/Applifier/go-bloomd/utils/clock"
@@ -20,7 +23,7 @@ import (
 // those timeouts are for tests not for benchmarks
 var manageOperationTimeout = 1 * time.Second
 var runtimeOperationTimeout = 10 * time.Millisecond
-var units = []clock.Unit{RollDaily, RollMonthly, RollWeekly}
+var units = []clock.Unit{RollDaily, RollMonthly, RollWeekly, RollHourly, RollMinutely}
 
 func TestOperations(t *testing.T) {
 	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
@@ -180,13 +183,529 @@ func TestFiltersManagement(t *testing.T) {
 	})
 }
 
+func TestShortUnits(t *testing.T) {
+	steps := map[clock.Unit]time.Duration{
+		RollHourly:                        time.Hour,
+		RollMinutely:                      time.Minute,
+		clock.DurationUnit(4 * time.Hour): 4 * time.Hour,
+	}
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		for unit, step := range steps {
+			t.Run("unit "+string(unit), func(t *testing.T) {
+				now := time.Now()
+				clock.Static(now)
+				defer clock.Reset()
+				rf := createFilter(t, c, "test_short_units_"+url.Scheme, 6, unit)
+				defer dropFilter(t, c, rf)
+				setShouldAddNew(t, c, rf, "foo")
+
+				clock.Static(now.Add(5 * step))
+				if err := rf.CreateFilters(getContext(manageOperationTimeout), c, 0, 0, 0, true); err != nil {
+					t.Fatal(err)
+				}
+				found, err := rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("foo"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !found {
+					t.Error("foo should be found within period")
+				}
+
+				clock.Static(now.Add(6 * step))
+				if err := rf.CreateFilters(getContext(manageOperationTimeout), c, 0, 0, 0, true); err != nil {
+					t.Fatal(err)
+				}
+				found, err = rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("foo"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if found {
+					t.Error("foo should not be found out of period")
+				}
+			})
+		}
+	})
+}
+
+func TestCalendar(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		defer clock.Reset()
+		namer := namer.MustNewTimeUnitNamer("test_calendar_"+url.Scheme, RollDaily)
+		calendar := clock.NewCalendar(time.FixedZone("UTC+3", 3*60*60), time.Monday)
+		rf, err := NewFilter(namer, RollDaily, 1, WithCalendar(calendar))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := getContext(manageOperationTimeout)
+
+		// 2 Jan 00:30 and 04:00 in UTC+3 are different days in UTC
+		clock.Static(time.Date(2018, 1, 1, 21, 30, 0, 0, time.UTC))
+		if err := rf.CreateFilters(ctx, c, 0, 0, 0, true); err != nil {
+			t.Fatal(err)
+		}
+		defer dropFilter(t, c, rf)
+		if _, err := rf.Set(ctx, c, bloomd.Key("foo")); err != nil {
+			t.Fatal(err)
+		}
+		clock.Static(time.Date(2018, 1, 2, 1, 0, 0, 0, time.UTC))
+		found, err := rf.Check(ctx, c, bloomd.Key("foo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found {
+			t.Error("foo should be found during the same local day")
+		}
+		checkFilterExists(t, c, namer.NameFor(calendar.DayNumOf(clock.Now())))
+	})
+}
+
+func TestClock(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		now := time.Now()
+		namer := namer.MustNewTimeUnitNamer("test_clock_"+url.Scheme, RollWeekly)
+		currClock := clock.NewManualClock(now)
+		pastClock := clock.NewManualClock(now.Add(-period.Week))
+		curr, err := NewFilter(namer, RollWeekly, 1, WithClock(currClock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		past, err := NewFilter(namer, RollWeekly, 1, WithClock(pastClock))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := getContext(manageOperationTimeout)
+		if err := curr.CreateFilters(ctx, c, 0, 0, 0, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := past.CreateFilters(ctx, c, 0, 0, 0, true); err != nil {
+			t.Fatal(err)
+		}
+		defer dropFilter(t, c, curr)
+		defer dropFilter(t, c, past)
+
+		if _, err := curr.Set(ctx, c, bloomd.Key("foo")); err != nil {
+			t.Fatal(err)
+		}
+		if found, err := past.Check(ctx, c, bloomd.Key("foo")); err != nil || found {
+			t.Fatalf("foo should not be found a week ago (err %v)", err)
+		}
+		pastClock.Advance(period.Week)
+		if found, err := past.Check(ctx, c, bloomd.Key("foo")); err != nil || !found {
+			t.Fatalf("foo should be found after advancing the clock (err %v)", err)
+		}
+	})
+}
+
+func TestLongPeriod(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		now := time.Now()
+		clock.Static(now.Add(-179 * period.Day))
+		defer clock.Reset()
+		rf := createFilter(t, c, "test_long_period_"+url.Scheme, 180, RollDaily)
+		defer dropFilter(t, c, rf)
+		setShouldAddNew(t, c, rf, "foo")
+
+		clock.Static(now)
+		if err := rf.CreateFilters(getContext(manageOperationTimeout), c, 0, 0, 0, true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("bar")); err != nil {
+			t.Fatal(err)
+		}
+		results, err := rf.MultiCheck(getContext(manageOperationTimeout), c, readerReseter("foo", "baz", "bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer results.Close()
+		if !next(t, results) || next(t, results) || !next(t, results) {
+			t.Error("Wrong responses received")
+		}
+	})
+}
+
+func TestPipelinedCheck(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		now := time.Now()
+		clock.Static(now)
+		defer clock.Reset()
+		rf := createFilter(t, c, "test_pipelined_check_"+url.Scheme, 5, RollWeekly)
+		defer dropFilter(t, c, rf)
+		setShouldAddNew(t, c, rf, "newest")
+		clock.Static(now.Add(-4 * period.Week))
+		setShouldAddNew(t, c, rf, "oldest")
+		clock.Static(now)
+
+		// answers left after the key is found should be drained, so following checks stay consistent
+		for i := 0; i < 3; i++ {
+			for key, expected := range map[string]bool{"newest": true, "oldest": true, "missing": false} {
+				found, err := rf.Check(getContext(manageOperationTimeout), c, bloomd.Key(key))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if found != expected {
+					t.Errorf("%s is expected to be found %t", key, expected)
+				}
+			}
+		}
+	})
+}
+
+func TestDetailedCheck(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		now := time.Date(2018, 2, 14, 12, 0, 0, 0, time.UTC)
+		clock.Static(now)
+		defer clock.Reset()
+		rf := createFilter(t, c, "test_detailed_check_"+url.Scheme, 7, RollDaily)
+		defer dropFilter(t, c, rf)
+		for _, days := range []int{1, 3, 6} {
+			clock.Static(now.Add(-time.Duration(days) * period.Day))
+			setShouldAddNew(t, c, rf, fmt.Sprintf("foo-%d", days))
+			if _, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("bar")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		clock.Static(now)
+
+		t.Run("check detailed", func(t *testing.T) {
+			detail, err := rf.CheckDetailed(getContext(manageOperationTimeout), c, bloomd.Key("bar"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !detail.Found || detail.LastUnit != clock.DayNumOf(now)-1 {
+				t.Fatalf("bar should be found yesterday but was %+v", detail)
+			}
+			if !detail.From.Equal(time.Date(2018, 2, 13, 0, 0, 0, 0, time.UTC)) || !detail.To.Equal(time.Date(2018, 2, 14, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("wrong time range [%s, %s)", detail.From, detail.To)
+			}
+			if detail.Units.Count() != 3 || !detail.Units.Has(1) || !detail.Units.Has(3) || !detail.Units.Has(6) || detail.Units.Has(0) {
+				t.Errorf("wrong units %b", detail.Units)
+			}
+		})
+
+		t.Run("multi check detailed", func(t *testing.T) {
+			details, err := rf.MultiCheckDetailed(getContext(manageOperationTimeout), c, readerReseter("foo-6", "baz", "foo-3"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(details) != 3 {
+				t.Fatalf("3 details expected but got %d", len(details))
+			}
+			if !details[0].Found || details[0].LastUnit != clock.DayNumOf(now)-6 || details[0].Units.Count() != 1 {
+				t.Errorf("foo-6 should be found 6 days ago but was %+v", details[0])
+			}
+			if details[1].Found || details[1].Units.Count() != 0 {
+				t.Errorf("baz should not be found but was %+v", details[1])
+			}
+			if !details[2].Found || details[2].LastUnit != clock.DayNumOf(now)-3 {
+				t.Errorf("foo-3 should be found 3 days ago but was %+v", details[2])
+			}
+		})
+	})
+}
+
+func TestCountUnits(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		now := time.Date(2018, 2, 14, 12, 0, 0, 0, time.UTC)
+		clock.Static(now)
+		defer clock.Reset()
+		rf := createFilter(t, c, "test_count_units_"+url.Scheme, 7, RollDaily)
+		defer dropFilter(t, c, rf)
+		for _, days := range []int{0, 2, 3, 6} {
+			clock.Static(now.Add(-time.Duration(days) * period.Day))
+			if _, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("foo")); err != nil {
+				t.Fatal(err)
+			}
+			if days%3 == 0 {
+				if _, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("bar")); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		clock.Static(now)
+
+		cs, err := rf.CountUnits(getContext(manageOperationTimeout), c, readerReseter("foo", "baz", "bar"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cs.Close()
+		expected := []int{4, 0, 3}
+		if cs.Length() != len(expected) {
+			t.Fatalf("%d counts expected but got %d", len(expected), cs.Length())
+		}
+		for i, count := range expected {
+			if cs.Count(i) != count {
+				t.Errorf("expected %d key to be found in %d units but was %d", i, count, cs.Count(i))
+			}
+		}
+	})
+}
+
+func TestMissingFilterPolicy(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		clk := clock.NewManualClock(time.Now())
+		namer := namer.MustNewTimeUnitNamer("test_missing_filter_policy_"+url.Scheme, RollDaily)
+		filterWithPolicy := func(policy aggregation.MissingFilterPolicy) *Filter {
+			rf, err := NewFilter(namer, RollDaily, 5, WithClock(clk), WithMissingFilterPolicy(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return rf
+		}
+		rf := filterWithPolicy(aggregation.MissingFilterError)
+		defer dropFilter(t, c, rf)
+		// only the current filter exists
+		if _, err := c.CreateFilter(rf.nameForUnit(rf.currUnit()), 0, 0, true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("foo")); err != nil {
+			t.Fatal(err)
+		}
+		filtersCount := func() int {
+			fs, err := rf.findFilters(getContext(manageOperationTimeout), c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return len(fs)
+		}
+		multiCheck := func(rf *Filter) ([]bool, error) {
+			results, err := rf.MultiCheck(getContext(manageOperationTimeout), c, readerReseter("foo", "bar"))
+			if err != nil {
+				return nil, err
+			}
+			defer results.Close()
+			found := make([]bool, results.Length())
+			_, err = results.Read(found)
+			return found, err
+		}
+
+		t.Run("error", func(t *testing.T) {
+			if _, err := multiCheck(rf); err != bloomd.ErrFilterNotExist {
+				t.Errorf("ErrFilterNotExist expected but got %v", err)
+			}
+			if _, err := rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("bar")); err != bloomd.ErrFilterNotExist {
+				t.Errorf("ErrFilterNotExist expected but got %v", err)
+			}
+		})
+
+		t.Run("empty", func(t *testing.T) {
+			rf := filterWithPolicy(aggregation.MissingFilterEmpty)
+			found, err := multiCheck(rf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found[0] || found[1] {
+				t.Errorf("only foo should be found but got %v", found)
+			}
+			if _, err := rf.CountUnits(getContext(manageOperationTimeout), c, readerReseter("foo")); err != nil {
+				t.Fatal(err)
+			}
+			if count := filtersCount(); count != 1 {
+				t.Errorf("missing filters should not be created but there are %d filters", count)
+			}
+		})
+
+		t.Run("create", func(t *testing.T) {
+			rf := filterWithPolicy(aggregation.MissingFilterCreate)
+			found, err := rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("bar"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found {
+				t.Error("bar should not be found")
+			}
+			if count := filtersCount(); count != 5 {
+				t.Errorf("all 5 filters of the period should exist but there are %d filters", count)
+			}
+			found, err = rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("foo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Error("foo should be found")
+			}
+		})
+	})
+}
+
+func TestFilterSpec(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		clk := clock.NewManualClock(time.Now())
+		namer := namer.MustNewTimeUnitNamer("test_filter_spec_"+url.Scheme, RollDaily)
+		rf, err := NewFilter(namer, RollDaily, 3, WithClock(clk), WithFilterSpec(ondemand.FilterSpec{Capacity: 50000, Prob: 0.001, InMemory: true}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dropFilter(t, c, rf)
+
+		t.Run("set creates filter", func(t *testing.T) {
+			added, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("foo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !added {
+				t.Error("foo should be added")
+			}
+			info, err := c.GetFilter(rf.nameForUnit(rf.currUnit())).Info()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info["capacity"] != "50000" {
+				t.Errorf("filter should be created with capacity from spec but was %s", info["capacity"])
+			}
+		})
+
+		t.Run("bulk set creates filter", func(t *testing.T) {
+			clk.Advance(period.Day)
+			results, err := rf.BulkSet(getContext(manageOperationTimeout), c, bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer results.Close()
+			added := make([]bool, 2)
+			if _, err := results.Read(added); err != nil {
+				t.Fatal(err)
+			}
+			if !added[0] || !added[1] {
+				t.Errorf("both keys should be added but got %v", added)
+			}
+			found, err := rf.Check(getContext(manageOperationTimeout), c, bloomd.Key("foo"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Error("foo should be found")
+			}
+		})
+
+		t.Run("filter is created once", func(t *testing.T) {
+			if err := c.GetFilter(rf.nameForUnit(rf.currUnit())).Drop(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := rf.Set(getContext(manageOperationTimeout), c, bloomd.Key("foo")); err != bloomd.ErrFilterNotExist {
+				t.Errorf("ErrFilterNotExist expected but got %v", err)
+			}
+		})
+	})
+}
+
+func TestParallelMultiCheck(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		cp := createClientPoolFromURL(t, url)
+		defer cp.Close()
+		now := time.Now()
+		clock.Static(now)
+		defer clock.Reset()
+		rf := createFilter(t, c, "test_parallel_multicheck_"+url.Scheme, 5, RollWeekly)
+		defer dropFilter(t, c, rf)
+		for i := 0; i < 5; i++ {
+			clock.Static(now.Add(-time.Duration(i) * period.Week))
+			setShouldAddNew(t, c, rf, fmt.Sprintf("foo-%d", i))
+		}
+		clock.Static(now)
+
+		for _, concurrency := range []int{0, 2, 10} {
+			t.Run(fmt.Sprintf("concurrency %d", concurrency), func(t *testing.T) {
+				results, err := rf.ParallelMultiCheck(getContext(manageOperationTimeout), cp, readerReseter("foo-0", "foo-3", "bar", "foo-4"), concurrency)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer results.Close()
+				if results.Length() != 4 {
+					t.Fatalf("4 results expected but got %d", results.Length())
+				}
+				if !next(t, results) || !next(t, results) || next(t, results) || !next(t, results) {
+					t.Error("Wrong responses received")
+				}
+			})
+		}
+
+		t.Run("cancelled context", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := rf.ParallelMultiCheck(ctx, cp, readerReseter("foo-0"), 2); err != context.Canceled {
+				t.Errorf("context error expected but got %v", err)
+			}
+		})
+	})
+}
+
+func TestParallelMultiCheckCancel(t *testing.T) {
+	server, err := mock.NewListener("tcp://127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+	cp, err := bloomd.NewPoolFromAddr(2, 2, server.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cp.Close()
+	c := getClientFromPool(t, cp)
+	rf := createFilter(t, c, "test_parallel_multicheck_cancel", 4, RollDaily)
+	c.Close()
+	server.SetFaultPlan(mock.NewFaultPlan(1).Add(mock.Fault{Command: "m", Latency: 5 * time.Second}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	started := time.Now()
+	if _, err := rf.ParallelMultiCheck(ctx, cp, readerReseter("foo"), 2); err != context.Canceled {
+		t.Errorf("context error expected but got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("check should be interrupted on cancel but took %s", elapsed)
+	}
+}
+
+func TestMaxPeriod(t *testing.T) {
+	namer := namer.MustNewTimeUnitNamer("test_max_period", RollDaily)
+	if _, err := NewFilter(namer, RollDaily, 180); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFilter(namer, RollDaily, DefaultMaxPeriod+1); err == nil {
+		t.Fatal("period wider than default max period should be rejected")
+	}
+	if _, err := NewFilter(namer, RollDaily, DefaultMaxPeriod+1, WithMaxPeriod(DefaultMaxPeriod*2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFilter(namer, RollDaily, 10, WithMaxPeriod(5)); err == nil {
+		t.Fatal("period wider than max period should be rejected")
+	}
+	if _, err := NewFilter(namer, RollDaily, 0); err == nil {
+		t.Fatal("empty period should be rejected")
+	}
+}
+
 func Disabled_BenchmarkOperationsParallel(b *testing.B) {
 	for _, addr := range testutils.BloomdAddrs() {
 		url := testutils.ParseURL(b, addr)
 		b.Run("Test address "+addr, func(b *testing.B) {
 			c := createClientFromURL(b, url)
 			cp := createClientPoolFromURL(b, url)
-			periods := []clock.UnitNum{1, 5, 10}
+			periods := []clock.UnitNum{1, 5, 10, 50, 180}
 			for _, period := range periods {
 				b.Run(fmt.Sprintf("MultiCheck-p%d", period), func(b *testing.B) {
 					rf := createBenchFilter(b, c, fmt.Sprintf("bench_operations_multicheck_%d_%s", period, url.Scheme), period, RollWeekly)
@@ -243,10 +762,45 @@ func BenchmarkOperations(b *testing.B) {
 		url := testutils.ParseURL(b, addr)
 		b.Run("Test address "+addr, func(b *testing.B) {
 			c := createClientFromURL(b, url)
-			periods := []clock.UnitNum{1, 5, 10}
+			periods := []clock.UnitNum{1, 5, 10, 50, 180}
 			ks := generateSeqKeyReaderReseter(100)
 			readResults := make([]bool, 100)
 			for _, period := range periods {
+				b.Run(fmt.Sprintf("ParallelMultiCheck-p%d", period), func(b *testing.B) {
+					rf := createBenchFilter(b, c, fmt.Sprintf("bench_operations_parallel_multicheck_%d_%s", period, url.Scheme), period, RollWeekly)
+					defer dropFilter(b, c, rf)
+					cp := createClientPoolFromURL(b, url)
+					defer cp.Close()
+
+					b.ResetTimer()
+
+					for i := 0; i < b.N; i++ {
+						ks.Reset()
+						rr, err := rf.ParallelMultiCheck(context.Background(), cp, ks, 4)
+						if err != nil {
+							b.Fatal(err)
+						}
+						_, err = rr.Read(readResults)
+						if err != nil {
+							b.Fatal(err)
+						}
+						rr.Close()
+					}
+				})
+
+				b.Run(fmt.Sprintf("Check-p%d", period), func(b *testing.B) {
+					rf := createBenchFilter(b, c, fmt.Sprintf("bench_operations_check_%d_%s", period, url.Scheme), period, RollWeekly)
+					defer dropFilter(b, c, rf)
+
+					b.ResetTimer()
+
+					for i := 0; i < b.N; i++ {
+						if _, err := rf.Check(context.Background(), c, bloomd.Key("missing")); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+
 				b.Run(fmt.Sprintf("MultiCheck-p%d", period), func(b *testing.B) {
 					rf := createBenchFilter(b, c, fmt.Sprintf("bench_operations_multicheck_%d_%s", period, url.Scheme), period, RollWeekly)
 					defer dropFilter(b, c, rf)
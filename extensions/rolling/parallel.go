@@ -0,0 +1,145 @@
+package rolling
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/aggregation"
+	"github.com/Applifier/go-bloomd/extensions/input"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// ParallelMultiCheck checks filters through period using up to concurrency clients of the pool at once
+// filters of the period are split between clients, each client pipelines commands to its filters
+// and results of all clients are merged using "or" logical operation
+// cancelling ctx interrupts reads in flight
+// missing filters are treated according to the missing filter policy
+func (rf *Filter) ParallelMultiCheck(ctx context.Context, pool *bloomd.Pool, rr input.KeyReaderReseter, concurrency int) (bloomd.ResultReader, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if clock.UnitNum(concurrency) > rf.period {
+		concurrency = int(rf.period)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var keys []bloomd.Key
+	for rr.Next() {
+		keys = append(keys, append(bloomd.Key(nil), rr.Current()...))
+	}
+	defer rr.Reset()
+
+	currUnit := rf.currUnit()
+	var (
+		lock     sync.Mutex
+		wg       sync.WaitGroup
+		rs       *aggregation.ResultsSet
+		firstErr error
+	)
+	// merge adds partial results of a client to the aggregated ones
+	merge := func(partial *aggregation.ResultsSet, err error) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch {
+		case err != nil:
+			if firstErr == nil {
+				firstErr = err
+			}
+		case rs == nil:
+			rs = partial
+		default:
+			err = rs.MergeFromReader(partial)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	chunk := (int(rf.period) + concurrency - 1) / concurrency
+	for from := 0; from < int(rf.period); from += chunk {
+		to := from + chunk
+		if to > int(rf.period) {
+			to = int(rf.period)
+		}
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			merge(rf.multiCheckUnits(ctx, pool, keys, currUnit-clock.UnitNum(from), to-from))
+		}(from, to)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		if rs != nil {
+			rs.Close()
+		}
+		return nil, firstErr
+	}
+	return rs, nil
+}
+
+// multiCheckUnits checks count filters starting from the unit and going to the past through a single client
+func (rf *Filter) multiCheckUnits(ctx context.Context, pool *bloomd.Pool, keys []bloomd.Key, unit clock.UnitNum, count int) (*aggregation.ResultsSet, error) {
+	cli, err := pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	defer interruptOnDone(ctx, cli)()
+	pipeline := cli.Pipeline()
+	for i := 0; i < count; i++ {
+		pipeline.MultiCheck(rf.nameForUnit(unit-clock.UnitNum(i)), bloomd.NewArrayReader(keys...))
+	}
+	var (
+		rs      *aggregation.ResultsSet
+		missing []clock.UnitNum
+	)
+	err = pipeline.Exec(func(i int, reader bloomd.ResultReader) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reader = rf.missingPolicy.Results(reader, unit-clock.UnitNum(i), &missing)
+		if i == 0 {
+			rs = aggregation.GetResultSet(reader.Length())
+			return rs.FillFromReader(reader)
+		}
+		return rs.MergeFromReader(reader)
+	})
+	if err == nil {
+		err = rf.missingPolicy.CreateMissing(cli, missing, rf.createOnce)
+	}
+	if err != nil {
+		if rs != nil {
+			rs.Close()
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, err
+	}
+	return rs, nil
+}
+
+// interruptOnDone sets deadline of the client to now once ctx is done, so reads in flight fail even if ctx has no deadline
+// returned func stops watching ctx, it must be called before the client is closed
+func interruptOnDone(ctx context.Context, cli *bloomd.Client) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			cli.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
@@ -0,0 +1,49 @@
+package rolling
+
+import (
+	"context"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/input"
+)
+
+// NewPoolFilter binds rolling filter to a pool of clients, so it can be used as bloomd.BloomFilter
+func NewPoolFilter(filter *Filter, pool *bloomd.Pool) *bloomd.PoolFilter {
+	return bloomd.BindPoolFilter(pool, filter.Bind)
+}
+
+// Bind binds filter to cli and ctx, so it can be used as bloomd.KeyFilter
+func (rf *Filter) Bind(ctx context.Context, cli *bloomd.Client) bloomd.KeyFilter {
+	return boundFilter{
+		filter: rf,
+		ctx:    ctx,
+		cli:    cli,
+	}
+}
+
+type boundFilter struct {
+	filter *Filter
+	ctx    context.Context
+	cli    *bloomd.Client
+}
+
+// Set sets key to filter that corresponds to a latest unit
+func (bf boundFilter) Set(k bloomd.Key) (bool, error) {
+	return bf.filter.Set(bf.ctx, bf.cli, k)
+}
+
+// Check checks key in filters through period
+func (bf boundFilter) Check(k bloomd.Key) (bool, error) {
+	return bf.filter.Check(bf.ctx, bf.cli, k)
+}
+
+// BulkSet sets keys to filter that corresponds to a latest unit
+func (bf boundFilter) BulkSet(reader bloomd.KeyReader) (bloomd.ResultReader, error) {
+	return bf.filter.BulkSet(bf.ctx, bf.cli, reader)
+}
+
+// MultiCheck checks keys in filters through period
+// keys are read into memory if reader can not be reset
+func (bf boundFilter) MultiCheck(reader bloomd.KeyReader) (bloomd.ResultReader, error) {
+	return bf.filter.MultiCheck(bf.ctx, bf.cli, input.ToReaderReseter(reader))
+}
@@ -0,0 +1,25 @@
+package rolling
+
+import (
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/extensions/ondemand"
+	"github.com/Applifier/go-bloomd/utils/clock"
+)
+
+// WithFilterSpec makes writes create the filter of the current unit with spec if it does not exist and retry once
+// filters created by the missing filter policy use spec as well
+func WithFilterSpec(spec ondemand.FilterSpec) Option {
+	return func(rf *Filter) {
+		rf.spec = &spec
+	}
+}
+
+// createOnce creates filter of the unit unless it was already created on demand by this filter
+func (rf *Filter) createOnce(cli *bloomd.Client, unit clock.UnitNum) error {
+	var spec ondemand.FilterSpec
+	if rf.spec != nil {
+		spec = *rf.spec
+	}
+	// units out of the period will not be created again
+	return rf.creator.CreateOnce(cli, unit, rf.nameForUnit(unit), spec, rf.currUnit()-rf.period)
+}
@@ -15,6 +15,9 @@ var yes = []byte("Yes")
 
 // BulkSet adds multiple keys to the filter
 func (f Filter) BulkSet(reader KeyReader) (ResultReader, error) {
+	if f.client.cache != nil {
+		return f.cachedBatchOp("b", reader)
+	}
 	count, err := f.sendBatchOp("b", reader)
 	if err != nil {
 		return nil, f.client.handleWriteError(err)
@@ -24,7 +27,11 @@ func (f Filter) BulkSet(reader KeyReader) (ResultReader, error) {
 }
 
 // MultiCheck checks multiple keys for the filter
+// if the client has a cache only keys missing from it are sent to the server
 func (f Filter) MultiCheck(reader KeyReader) (ResultReader, error) {
+	if f.client.cache != nil {
+		return f.cachedBatchOp("m", reader)
+	}
 	count, err := f.sendBatchOp("m", reader)
 	if err != nil {
 		return nil, f.client.handleWriteError(err)
@@ -33,7 +40,43 @@ func (f Filter) MultiCheck(reader KeyReader) (ResultReader, error) {
 	return f.readerFor(count), nil
 }
 
+func (f Filter) cachedBatchOp(op string, reader KeyReader) (ResultReader, error) {
+	keys := copyKeys(reader)
+	r := &cachedResultReader{
+		cache:      f.client.cache,
+		filterName: f.Name,
+		generation: f.client.cache.generation(f.Name),
+		keys:       keys,
+		setOp:      op == "b",
+	}
+	if !r.setOp {
+		misses := 0
+		r.cached = make([]bool, len(keys))
+		for i, key := range keys {
+			r.cached[i] = r.cache.contains(f.Name, key)
+			if !r.cached[i] {
+				misses++
+			}
+		}
+		if misses == 0 && len(keys) > 0 {
+			return r, nil
+		}
+	}
+	count, err := f.sendBatchOp(op, &uncachedKeyReader{keys: keys, cached: r.cached})
+	if err != nil {
+		return nil, f.client.handleWriteError(err)
+	}
+	r.remote = f.readerFor(count)
+	return r, nil
+}
+
 func (f Filter) sendBatchOp(op string, reader KeyReader) (int, error) {
+	count := f.writeBatchOp(op, reader)
+	return count, f.client.writer.Flush()
+}
+
+// writeBatchOp writes batch command to the buffer without flushing it
+func (f Filter) writeBatchOp(op string, reader KeyReader) int {
 	count := 0
 	w := f.client.writer
 	w.WriteString(op)
@@ -45,11 +88,14 @@ func (f Filter) sendBatchOp(op string, reader KeyReader) (int, error) {
 		w.Write(reader.Current())
 	}
 	w.WriteByte(cmdDelimeter)
-	return count, w.Flush()
+	return count
 }
 
 // Clear clears the filter
+// cached keys are invalidated both before and after the request, so results of concurrent requests are not cached
 func (f Filter) Clear() error {
+	f.invalidateCache()
+	defer f.invalidateCache()
 	return checkResponse(f.client.sendAndReceive([]byte("clear " + f.Name)))
 }
 
@@ -59,7 +105,10 @@ func (f Filter) Close() error {
 }
 
 // Drop drops the filter on the server
+// cached keys are invalidated both before and after the request, so results of concurrent requests are not cached
 func (f Filter) Drop() error {
+	f.invalidateCache()
+	defer f.invalidateCache()
 	return checkResponse(f.client.sendAndReceive([]byte("drop " + f.Name)))
 }
 
@@ -91,25 +140,53 @@ func (f Filter) Info() (map[string]string, error) {
 
 // Set sets a single key to the bloom
 func (f Filter) Set(key Key) (bool, error) {
+	cache := f.client.cache
+	var generation uint64
+	if cache != nil {
+		generation = cache.generation(f.Name)
+	}
 	err := f.sendSingleOp("s", key)
 	if err != nil {
 		return false, f.client.handleWriteError(err)
 	}
 
-	return f.readSingle()
+	result, err := f.readSingle()
+	if err == nil && cache != nil {
+		cache.add(f.Name, key, generation)
+	}
+	return result, err
 }
 
 // Check gets a single key to the bloom
+// if the client has a cache keys known to be present are not sent to the server
 func (f Filter) Check(key Key) (bool, error) {
+	cache := f.client.cache
+	var generation uint64
+	if cache != nil {
+		if cache.contains(f.Name, key) {
+			return true, nil
+		}
+		generation = cache.generation(f.Name)
+	}
 	err := f.sendSingleOp("c", key)
 	if err != nil {
 		return false, f.client.handleWriteError(err)
 	}
 
-	return f.readSingle()
+	result, err := f.readSingle()
+	if result && err == nil && cache != nil {
+		cache.add(f.Name, key, generation)
+	}
+	return result, err
 }
 
 func (f Filter) sendSingleOp(op string, key Key) error {
+	f.writeSingleOp(op, key)
+	return f.client.writer.Flush()
+}
+
+// writeSingleOp writes single key command to the buffer without flushing it
+func (f Filter) writeSingleOp(op string, key Key) {
 	w := f.client.writer
 	w.WriteString(op)
 	w.WriteByte(itemDelimeter)
@@ -117,7 +194,6 @@ func (f Filter) sendSingleOp(op string, key Key) error {
 	w.WriteByte(itemDelimeter)
 	w.Write(key)
 	w.WriteByte(cmdDelimeter)
-	return w.Flush()
 }
 
 func (f Filter) readerFor(resultLength int) ResultReader {
@@ -131,7 +207,17 @@ func (f Filter) readSingle() (bool, error) {
 	return r.Next()
 }
 
+// invalidateCache forgets keys of the filter cached by the client
+func (f Filter) invalidateCache() {
+	if f.client.cache != nil {
+		f.client.cache.invalidate(f.Name)
+	}
+}
+
 func checkResponse(resp string, err error) error {
+	if resp == ErrFilterNotExist.Error() {
+		return ErrFilterNotExist
+	}
 	if resp != "Done" {
 		return Error{
 			Message: "invalid response from server: " + resp,
@@ -90,6 +90,44 @@ func TestFilter(t *testing.T) {
 	})
 }
 
+func TestFilterNotExist(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		f := c.GetFilter("not_existing_filter_" + url.Scheme)
+
+		t.Run("check key", func(t *testing.T) {
+			if _, err := f.Check(Key("foo")); err != ErrFilterNotExist {
+				t.Fatalf("ErrFilterNotExist expected but got %v", err)
+			}
+		})
+
+		t.Run("check multiple keys", func(t *testing.T) {
+			resps, err := f.MultiCheck(NewArrayReader(Key("foo"), Key("bar")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := resps.Next(); err != ErrFilterNotExist {
+				t.Fatalf("ErrFilterNotExist expected but got %v", err)
+			}
+			if err := resps.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		t.Run("drop", func(t *testing.T) {
+			if err := f.Drop(); err != ErrFilterNotExist {
+				t.Fatalf("ErrFilterNotExist expected but got %v", err)
+			}
+		})
+
+		t.Run("client is usable afterwards", func(t *testing.T) {
+			if err := c.Ping(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	})
+}
+
 func next(t *testing.T, reader ResultReader) bool {
 	next, err := reader.Next()
 	if err != nil {
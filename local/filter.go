@@ -0,0 +1,202 @@
+package local
+
+import (
+	"strconv"
+	"sync"
+
+	bloomd "github.com/Applifier/go-bloomd"
+	"github.com/Applifier/go-bloomd/utils/bloom"
+)
+
+// DefaultCapacity is the initial capacity used when none is provided, the same as in bloomd
+var DefaultCapacity = 100000
+
+// DefaultProbability is the false positive probability used when none is provided, the same as in bloomd
+var DefaultProbability = 0.0001
+
+// ScaleSize is a growth factor of the capacity of each next layer of the filter
+var ScaleSize = 4
+
+// ProbabilityReduction is a factor of the false positive probability of each next layer of the filter
+var ProbabilityReduction = 0.9
+
+// Filter is an in-process scalable bloom filter with the same operations as bloomd.Filter
+// once a layer reaches its capacity a new bigger layer with a lower probability is added,
+// so the overall probability of false positives stays within the configured one
+type Filter struct {
+	Name string
+
+	lock     sync.Mutex
+	capacity int
+	prob     float64
+	layers   []*bloom.Filter
+
+	checks      uint64
+	checkHits   uint64
+	checkMisses uint64
+	sets        uint64
+	setHits     uint64
+	setMisses   uint64
+}
+
+// NewFilter creates a new filter, zero capacity and prob are replaced with defaults
+func NewFilter(name string, capacity int, prob float64) (*Filter, error) {
+	if capacity == 0 {
+		capacity = DefaultCapacity
+	}
+	if prob == 0 {
+		prob = DefaultProbability
+	}
+	if capacity < 1 {
+		return nil, bloomd.Error{Message: "Invalid capacity"}
+	}
+	if prob < 0 || prob >= 1 {
+		return nil, bloomd.Error{Message: "Invalid probability"}
+	}
+	f := &Filter{
+		Name:     name,
+		capacity: capacity,
+		prob:     prob,
+	}
+	f.reset()
+	return f, nil
+}
+
+// BulkSet adds multiple keys to the filter
+func (f *Filter) BulkSet(reader bloomd.KeyReader) (bloomd.ResultReader, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	results := newResultReader()
+	for reader.Next() {
+		results.append(f.set(reader.Current()))
+	}
+	return results, nil
+}
+
+// MultiCheck checks multiple keys for the filter
+func (f *Filter) MultiCheck(reader bloomd.KeyReader) (bloomd.ResultReader, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	results := newResultReader()
+	for reader.Next() {
+		results.append(f.check(reader.Current()))
+	}
+	return results, nil
+}
+
+// Set sets a single key to the bloom, it returns true if the key was not present
+func (f *Filter) Set(key bloomd.Key) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.set(key), nil
+}
+
+// Check checks a single key in the bloom
+func (f *Filter) Check(key bloomd.Key) (bool, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.check(key), nil
+}
+
+// Clear removes all keys from the filter
+func (f *Filter) Clear() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.reset()
+	return nil
+}
+
+// Drop removes all keys from the filter, there is no server to drop it from
+func (f *Filter) Drop() error {
+	return f.Clear()
+}
+
+// Close does nothing, it exists for compatibility with bloomd.Filter
+func (f *Filter) Close() error {
+	return nil
+}
+
+// Flush does nothing, it exists for compatibility with bloomd.Filter
+func (f *Filter) Flush() error {
+	return nil
+}
+
+// Info returns the same info map as bloomd does
+func (f *Filter) Info() (map[string]string, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	capacity, size, storage := 0, 0, uint64(0)
+	for _, l := range f.layers {
+		capacity += l.Capacity()
+		size += l.Count()
+		storage += l.Bytes()
+	}
+	return map[string]string{
+		"capacity":     strconv.Itoa(capacity),
+		"checks":       strconv.FormatUint(f.checks, 10),
+		"check_hits":   strconv.FormatUint(f.checkHits, 10),
+		"check_misses": strconv.FormatUint(f.checkMisses, 10),
+		"in_memory":    "1",
+		"page_ins":     "0",
+		"page_outs":    "0",
+		"probability":  strconv.FormatFloat(f.prob, 'f', 6, 64),
+		"sets":         strconv.FormatUint(f.sets, 10),
+		"set_hits":     strconv.FormatUint(f.setHits, 10),
+		"set_misses":   strconv.FormatUint(f.setMisses, 10),
+		"size":         strconv.Itoa(size),
+		"storage":      strconv.FormatUint(storage, 10),
+	}, nil
+}
+
+func (f *Filter) set(key bloomd.Key) bool {
+	f.sets++
+	if f.contains(key) {
+		f.setMisses++
+		return false
+	}
+	last := f.layers[len(f.layers)-1]
+	if last.Count() >= last.Capacity() {
+		last = f.addLayer()
+	}
+	last.Add(key)
+	f.setHits++
+	return true
+}
+
+func (f *Filter) check(key bloomd.Key) bool {
+	f.checks++
+	if f.contains(key) {
+		f.checkHits++
+		return true
+	}
+	f.checkMisses++
+	return false
+}
+
+func (f *Filter) contains(key bloomd.Key) bool {
+	for i := len(f.layers) - 1; i >= 0; i-- {
+		if f.layers[i].Test(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) reset() {
+	f.layers = nil
+	f.addLayer()
+}
+
+// addLayer adds a layer with probability prob*(1-r)*r^i, so the sum over all layers never exceeds prob
+func (f *Filter) addLayer() *bloom.Filter {
+	capacity := f.capacity
+	prob := f.prob * (1 - ProbabilityReduction)
+	if n := len(f.layers); n > 0 {
+		last := f.layers[n-1]
+		capacity = last.Capacity() * ScaleSize
+		prob = last.Probability() * ProbabilityReduction
+	}
+	l := bloom.New(capacity, prob)
+	f.layers = append(f.layers, l)
+	return l
+}
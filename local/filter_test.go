@@ -0,0 +1,159 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+func TestFilter(t *testing.T) {
+	f, err := NewFilter("local", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("set key", func(t *testing.T) {
+		isNew, err := f.Set(bloomd.Key("foo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !isNew {
+			t.Error("foo should be new")
+		}
+	})
+
+	t.Run("check key", func(t *testing.T) {
+		found, err := f.Check(bloomd.Key("foo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !found {
+			t.Error("foo should be found")
+		}
+	})
+
+	t.Run("set multiple keys", func(t *testing.T) {
+		results, err := f.BulkSet(bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar"), bloomd.Key("baz")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer results.Close()
+		expectResults(t, results, false, true, true)
+	})
+
+	t.Run("check multiple keys", func(t *testing.T) {
+		results, err := f.MultiCheck(bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar"), bloomd.Key("baz"), bloomd.Key("biz")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer results.Close()
+		expectResults(t, results, true, true, true, false)
+	})
+
+	t.Run("info", func(t *testing.T) {
+		info, err := f.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := map[string]string{
+			"capacity":    "100000",
+			"checks":      "5",
+			"check_hits":  "4",
+			"probability": "0.000100",
+			"sets":        "4",
+			"set_hits":    "3",
+			"size":        "3",
+		}
+		for k, v := range expected {
+			if info[k] != v {
+				t.Errorf("expected %s to be %s but was %s", k, v, info[k])
+			}
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		if err := f.Clear(); err != nil {
+			t.Fatal(err)
+		}
+		found, err := f.Check(bloomd.Key("foo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found {
+			t.Error("foo should not be found after clear")
+		}
+	})
+}
+
+func TestFilterScaling(t *testing.T) {
+	f, err := NewFilter("scaling", 100, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10000; i++ {
+		if _, err := f.Set(bloomd.Key(fmt.Sprintf("key_%d", i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		if found, _ := f.Check(bloomd.Key(fmt.Sprintf("key_%d", i))); !found {
+			t.Fatalf("key_%d should be found", i)
+		}
+	}
+	positives := 0
+	for i := 0; i < 10000; i++ {
+		if found, _ := f.Check(bloomd.Key(fmt.Sprintf("other_%d", i))); found {
+			positives++
+		}
+	}
+	if positives > 200 {
+		t.Errorf("false positives expected to be below 1%% but were %d", positives)
+	}
+	info, _ := f.Info()
+	if info["capacity"] != "34100" {
+		t.Errorf("capacity expected to grow to 34100 but was %s", info["capacity"])
+	}
+}
+
+func TestNewFilterValidation(t *testing.T) {
+	if _, err := NewFilter("invalid", -1, 0); err == nil {
+		t.Error("negative capacity should be rejected")
+	}
+	if _, err := NewFilter("invalid", 0, 1); err == nil {
+		t.Error("probability of 1 should be rejected")
+	}
+}
+
+func TestFilterWithContext(t *testing.T) {
+	f, err := NewFilter("context", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bf bloomd.BloomFilter = bloomd.WithContext(f)
+	if _, err := bf.Set(context.Background(), bloomd.Key("foo")); err != nil {
+		t.Fatal(err)
+	}
+	results, err := bf.MultiCheck(context.Background(), bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectResults(t, results, true, false)
+}
+
+func expectResults(t *testing.T, results bloomd.ResultReader, expected ...bool) {
+	t.Helper()
+	if results.Length() != len(expected) {
+		t.Fatalf("expected %d results but got %d", len(expected), results.Length())
+	}
+	for i, e := range expected {
+		r, err := results.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r != e {
+			t.Errorf("result %d expected to be %v but was %v", i, e, r)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package local
+
+import (
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+// resultReader is bloomd.ResultReader over results computed in memory
+type resultReader struct {
+	results []bool
+	cursor  int
+}
+
+func newResultReader() *resultReader {
+	return &resultReader{}
+}
+
+func (r *resultReader) append(result bool) {
+	r.results = append(r.results, result)
+}
+
+func (r *resultReader) Next() (bool, error) {
+	if r.cursor >= len(r.results) {
+		return false, bloomd.ErrCursorOverLength
+	}
+	r.cursor++
+	return r.results[r.cursor-1], nil
+}
+
+func (r *resultReader) Read(p []bool) (int, error) {
+	n := copy(p, r.results[r.cursor:])
+	r.cursor += n
+	return n, nil
+}
+
+func (r *resultReader) Length() int {
+	return len(r.results)
+}
+
+func (r *resultReader) Close() error {
+	r.cursor = len(r.results)
+	return nil
+}
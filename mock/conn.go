@@ -0,0 +1,106 @@
+package mock
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+)
+
+// session serves bloomd protocol over a single connection
+type session struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	respond func(ss *session, cmd string) error
+
+	lock     sync.Mutex
+	busy     bool
+	closing  bool
+	isClosed bool
+}
+
+func newSession(conn net.Conn, respond func(ss *session, cmd string) error) *session {
+	return &session{
+		conn:    conn,
+		reader:  bufio.NewReaderSize(conn, DefaultBufferSize),
+		respond: respond,
+	}
+}
+
+// serve handles commands until the client closes the connection or session is closed
+// io.EOF and errors caused by closing of the session are not reported
+func (ss *session) serve() error {
+	defer ss.close()
+	for {
+		cmd, err := ss.read()
+		if err != nil {
+			if err == io.EOF || ss.isClosing() {
+				return nil
+			}
+			return err
+		}
+		if !ss.setBusy(true) {
+			return nil
+		}
+		if err := ss.respond(ss, cmd); err != nil {
+			if ss.isClosing() {
+				return nil
+			}
+			return err
+		}
+		if !ss.setBusy(false) {
+			return nil
+		}
+	}
+}
+
+func (ss *session) read() (string, error) {
+	l, err := ss.reader.ReadString('\n')
+	if err != nil {
+		return l, err
+	}
+	return strings.TrimRight(l, "\r\n"), nil
+}
+
+func (ss *session) send(resp []byte) error {
+	_, err := ss.conn.Write(append(resp, '\n'))
+	return err
+}
+
+// setBusy marks session as processing a command, returns false if session should stop
+func (ss *session) setBusy(busy bool) bool {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	ss.busy = busy
+	return !ss.closing
+}
+
+func (ss *session) isClosing() bool {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	return ss.closing
+}
+
+// closeIfIdle closes connection if no command is being processed, returns true if session is closed
+func (ss *session) closeIfIdle() bool {
+	ss.lock.Lock()
+	ss.closing = true
+	busy := ss.busy
+	ss.lock.Unlock()
+	if busy {
+		return false
+	}
+	ss.close()
+	return true
+}
+
+func (ss *session) close() {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+	ss.closing = true
+	if !ss.isClosed {
+		ss.isClosed = true
+		ss.conn.Close()
+	}
+}
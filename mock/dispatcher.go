@@ -0,0 +1,133 @@
+package mock
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command is a command received by the server
+type Command struct {
+	// Time is when the command was received
+	Time time.Time
+	// Name is a short name of the command, e.g. "c" for both "c" and "check"
+	Name string
+	// Filter is a name of the filter the command targets, empty for list
+	Filter string
+	// Args are the rest of the command, like keys or create parameters
+	Args []string
+}
+
+// dispatcher answers commands of all sessions of a server from the store
+// it records received commands, checks them against expectations and injects faults into responses
+type dispatcher struct {
+	*Store
+
+	lock         sync.Mutex
+	faults       *FaultPlan
+	commands     []Command
+	expectations []*Expectation
+	unexpected   []Command
+}
+
+func newDispatcher(store *Store) *dispatcher {
+	return &dispatcher{
+		Store: store,
+	}
+}
+
+// SetFaultPlan makes server misbehave according to the plan, nil plan disables fault injection
+func (d *dispatcher) SetFaultPlan(plan *FaultPlan) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.faults = plan
+}
+
+func (d *dispatcher) faultPlan() *FaultPlan {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.faults
+}
+
+// Commands returns all commands received by the server in order of arrival
+func (d *dispatcher) Commands() []Command {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	commands := make([]Command, len(d.commands))
+	copy(commands, d.commands)
+	return commands
+}
+
+// ResetCommands forgets recorded commands, expectations and unexpected commands
+func (d *dispatcher) ResetCommands() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.commands = nil
+	d.expectations = nil
+	d.unexpected = nil
+}
+
+func (d *dispatcher) respond(ss *session, cmd string) error {
+	c := parseCommand(cmd)
+	resp, ok := d.record(c)
+	var fault *Fault
+	if plan := d.faultPlan(); plan != nil {
+		fault = plan.next(c.Name, c.Filter)
+	}
+	// commands failed by the server do not reach the store
+	if !ok && (fault == nil || !fault.failsCommand()) {
+		resp = d.handle(cmd)
+	}
+	if fault != nil {
+		return fault.apply(ss, resp)
+	}
+	return ss.send([]byte(resp))
+}
+
+// record saves command and matches it against expectations
+// it returns the response of the matched expectation if one was provided
+func (d *dispatcher) record(c Command) (string, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.commands = append(d.commands, c)
+	if len(d.expectations) == 0 {
+		return "", false
+	}
+	for _, e := range d.expectations {
+		if e.matches(c) && !e.exhausted() {
+			e.calls++
+			return e.response, e.hasResponse
+		}
+	}
+	d.unexpected = append(d.unexpected, c)
+	return "", false
+}
+
+var commandAliases = map[string]string{
+	"check": "c",
+	"multi": "m",
+	"set":   "s",
+	"bulk":  "b",
+}
+
+// parseCommand splits command into its name, filter and the rest of arguments
+func parseCommand(cmd string) Command {
+	c := Command{Time: time.Now()}
+	tokens := strings.Fields(cmd)
+	if len(tokens) == 0 {
+		return c
+	}
+	c.Name = tokens[0]
+	if alias, ok := commandAliases[c.Name]; ok {
+		c.Name = alias
+	}
+	args := tokens[1:]
+	if c.Name != "list" && len(args) > 0 {
+		c.Filter = args[0]
+		args = args[1:]
+	}
+	if len(args) > 0 {
+		c.Args = args
+	}
+	return c
+}
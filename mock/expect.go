@@ -0,0 +1,135 @@
+package mock
+
+import (
+	"strings"
+	"sync"
+)
+
+// TestReporter is the part of testing.TB used to report failed expectations
+type TestReporter interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Expectation describes a command the server is expected to receive
+// it is safe to configure an expectation while the server is receiving commands
+type Expectation struct {
+	// lock is the lock of the dispatcher matching commands against the expectation
+	lock sync.Locker
+
+	name    string
+	filter  string
+	args    []string
+	anyArgs bool
+
+	response    string
+	hasResponse bool
+	times       int
+	calls       int
+}
+
+// ExpectCommand registers expectation for a command with exact filter and arguments, by default it is expected once
+// once any expectation is registered, commands not matching any of them are reported by Verify as unexpected
+func (d *dispatcher) ExpectCommand(name string, filterName string, args ...string) *Expectation {
+	if alias, ok := commandAliases[name]; ok {
+		name = alias
+	}
+	e := &Expectation{
+		lock:   &d.lock,
+		name:   name,
+		filter: filterName,
+		args:   args,
+		times:  1,
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.expectations = append(d.expectations, e)
+	return e
+}
+
+// Return makes server answer with resp instead of executing the command against the store
+func (e *Expectation) Return(resp string) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.response = resp
+	e.hasResponse = true
+	return e
+}
+
+// Times sets how many times the command is expected
+func (e *Expectation) Times(n int) *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.times = n
+	return e
+}
+
+// AnyTimes allows the command to be received any number of times including zero
+func (e *Expectation) AnyTimes() *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.times = -1
+	return e
+}
+
+// AnyArgs makes expectation match the command regardless of its arguments
+func (e *Expectation) AnyArgs() *Expectation {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.anyArgs = true
+	return e
+}
+
+func (e *Expectation) matches(c Command) bool {
+	if e.name != c.Name || e.filter != c.Filter {
+		return false
+	}
+	if e.anyArgs {
+		return true
+	}
+	if len(e.args) != len(c.Args) {
+		return false
+	}
+	for i := range e.args {
+		if e.args[i] != c.Args[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Expectation) exhausted() bool {
+	return e.times >= 0 && e.calls >= e.times
+}
+
+func (e *Expectation) satisfied() bool {
+	return e.times < 0 || e.calls == e.times
+}
+
+func (e *Expectation) String() string {
+	return formatCommand(e.name, e.filter, e.args)
+}
+
+// Verify reports expectations that were not met and commands that were not expected
+func (d *dispatcher) Verify(t TestReporter) {
+	t.Helper()
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, e := range d.expectations {
+		if !e.satisfied() {
+			t.Errorf("mock: command %q expected %d times but was received %d times", e, e.times, e.calls)
+		}
+	}
+	for _, c := range d.unexpected {
+		t.Errorf("mock: unexpected command %q received at %s", formatCommand(c.Name, c.Filter, c.Args), c.Time)
+	}
+}
+
+func formatCommand(name string, filterName string, args []string) string {
+	tokens := make([]string, 0, len(args)+2)
+	tokens = append(tokens, name)
+	if filterName != "" {
+		tokens = append(tokens, filterName)
+	}
+	return strings.Join(append(tokens, args...), " ")
+}
@@ -0,0 +1,122 @@
+package mock
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+func TestExpectations(t *testing.T) {
+	t.Run("commands should be recorded", func(t *testing.T) {
+		server, client := newExpectingMock(t)
+		defer client.Close()
+		_, err := client.GetFilter("f1").Set(bloomd.Key("k"))
+		requireNoError(t, err)
+		_, err = client.ListFilters()
+		requireNoError(t, err)
+
+		commands := server.Commands()
+		if len(commands) != 3 {
+			t.Fatalf("expected 3 commands but got %d", len(commands))
+		}
+		last := commands[1]
+		if last.Name != "s" || last.Filter != "f1" || len(last.Args) != 1 || last.Args[0] != "k" || last.Time.IsZero() {
+			t.Fatalf("unexpected command recorded %+v", last)
+		}
+		if commands[2].Name != "list" || commands[2].Filter != "" {
+			t.Fatalf("unexpected command recorded %+v", commands[2])
+		}
+	})
+
+	t.Run("expected commands should be answered with provided response", func(t *testing.T) {
+		server, client := newExpectingMock(t)
+		defer client.Close()
+		server.ExpectCommand("c", "f1", "k").Return("Yes").Times(2)
+		server.ExpectCommand("s", "f1").AnyArgs().AnyTimes()
+
+		f := client.GetFilter("f1")
+		for i := 0; i < 2; i++ {
+			found, err := f.Check(bloomd.Key("k"))
+			requireNoError(t, err)
+			if !found {
+				t.Fatal("expected response should be returned")
+			}
+		}
+		_, err := f.Set(bloomd.Key("other"))
+		requireNoError(t, err)
+
+		reporter := &reporterMock{}
+		server.Verify(reporter)
+		if len(reporter.errors) != 0 {
+			t.Fatalf("no errors expected but got %v", reporter.errors)
+		}
+	})
+
+	t.Run("verify should report unmet and unexpected commands", func(t *testing.T) {
+		server, client := newExpectingMock(t)
+		defer client.Close()
+		server.ExpectCommand("check", "f1", "k").Times(2)
+		server.ExpectCommand("drop", "f1")
+
+		f := client.GetFilter("f1")
+		_, err := f.Check(bloomd.Key("k"))
+		requireNoError(t, err)
+		_, err = f.Check(bloomd.Key("other"))
+		requireNoError(t, err)
+
+		reporter := &reporterMock{}
+		server.Verify(reporter)
+		if len(reporter.errors) != 3 {
+			t.Fatalf("3 errors expected but got %v", reporter.errors)
+		}
+		for i, expected := range []string{`"c f1 k" expected 2 times but was received 1 times`, `"drop f1" expected 1 times`, `unexpected command "c f1 other"`} {
+			if !strings.Contains(reporter.errors[i], expected) {
+				t.Errorf("error %q expected to contain %q", reporter.errors[i], expected)
+			}
+		}
+	})
+
+	t.Run("expectations should be configurable while commands are received", func(t *testing.T) {
+		server, client := newExpectingMock(t)
+		defer client.Close()
+		e := server.ExpectCommand("c", "f1", "k").AnyTimes()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 100; i++ {
+				if _, err := client.GetFilter("f1").Check(bloomd.Key("k")); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+		for i := 0; i < 100; i++ {
+			e.Return("Yes").AnyArgs().Times(1000).AnyTimes()
+		}
+		<-done
+	})
+}
+
+type reporterMock struct {
+	errors []string
+}
+
+func (r *reporterMock) Helper() {}
+
+func (r *reporterMock) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newExpectingMock(t *testing.T) (*MockServer, *bloomd.Client) {
+	serverConn, clientConn := net.Pipe()
+	server := NewMockServer(serverConn)
+	go server.Serve()
+	client, err := bloomd.NewFromConn(clientConn)
+	requireNoError(t, err)
+	_, err = client.CreateFilter("f1", 0, 0, true)
+	requireNoError(t, err)
+	return server, client
+}
@@ -0,0 +1,156 @@
+package mock
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FaultAction defines how a response is corrupted by a fault
+type FaultAction int
+
+const (
+	// FaultNone keeps response intact, useful together with Latency
+	FaultNone FaultAction = iota
+	// FaultInternalError replies with bloomd internal error instead of executing the command
+	FaultInternalError
+	// FaultDropConnection closes the connection instead of executing the command
+	FaultDropConnection
+	// FaultTruncateResults removes the last token from Yes/No list
+	FaultTruncateResults
+	// FaultGarbageTokens replaces every token of the response with garbage
+	FaultGarbageTokens
+	// FaultSlowWrite writes response byte by byte waiting Pause between bytes
+	FaultSlowWrite
+	// FaultSlowRead stops reading from the connection for Pause after the response is written
+	FaultSlowRead
+)
+
+const garbageToken = "Garbage"
+
+// Fault describes when and how the server should misbehave
+type Fault struct {
+	// Command limits fault to a command, e.g. "c", "m" or "create", empty matches any command
+	Command string
+	// Filter limits fault to commands for a filter, empty matches any filter
+	Filter string
+	// Nth applies fault only to the nth matching command starting from 1, zero applies it to every one
+	Nth int
+	// Probability applies fault to a matching command with such probability, zero means always
+	Probability float64
+
+	// Latency delays the response
+	Latency time.Duration
+	// Action defines how the response is corrupted
+	Action FaultAction
+	// Pause is used by FaultSlowWrite and FaultSlowRead
+	Pause time.Duration
+}
+
+// FaultPlan is a set of faults, the first matching fault triggered for a command is applied
+type FaultPlan struct {
+	lock    sync.Mutex
+	faults  []Fault
+	matches []int
+	applied []int
+	rand    *rand.Rand
+}
+
+// NewFaultPlan creates an empty plan, seed is used to decide on probabilistic faults
+func NewFaultPlan(seed int64) *FaultPlan {
+	return &FaultPlan{
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Add appends fault to the plan
+func (p *FaultPlan) Add(fault Fault) *FaultPlan {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.faults = append(p.faults, fault)
+	p.matches = append(p.matches, 0)
+	p.applied = append(p.applied, 0)
+	return p
+}
+
+// Applied returns how many times the ith added fault was applied
+func (p *FaultPlan) Applied(i int) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.applied[i]
+}
+
+// next returns fault to apply for the command or nil
+func (p *FaultPlan) next(cmd string, filterName string) *Fault {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	var triggered *Fault
+	for i := range p.faults {
+		f := &p.faults[i]
+		if !f.matches(cmd, filterName) {
+			continue
+		}
+		p.matches[i]++
+		if triggered != nil {
+			continue
+		}
+		if f.Nth > 0 && p.matches[i] != f.Nth {
+			continue
+		}
+		if f.Probability > 0 && p.rand.Float64() >= f.Probability {
+			continue
+		}
+		p.applied[i]++
+		triggered = f
+	}
+	return triggered
+}
+
+func (f *Fault) matches(cmd string, filterName string) bool {
+	return (f.Command == "" || f.Command == cmd) && (f.Filter == "" || f.Filter == filterName)
+}
+
+// failsCommand tells if the fault means that the server failed, so the command should not change the store
+func (f *Fault) failsCommand() bool {
+	return f.Action == FaultInternalError || f.Action == FaultDropConnection
+}
+
+func (f *Fault) apply(ss *session, resp string) error {
+	if f.Latency > 0 {
+		time.Sleep(f.Latency)
+	}
+	switch f.Action {
+	case FaultInternalError:
+		return ss.send([]byte(respInternalError))
+	case FaultDropConnection:
+		ss.close()
+		return nil
+	case FaultTruncateResults:
+		tokens := strings.Split(resp, " ")
+		return ss.send([]byte(strings.Join(tokens[:len(tokens)-1], " ")))
+	case FaultGarbageTokens:
+		tokens := strings.Split(resp, " ")
+		for i := range tokens {
+			tokens[i] = garbageToken
+		}
+		return ss.send([]byte(strings.Join(tokens, " ")))
+	case FaultSlowWrite:
+		msg := append([]byte(resp), '\n')
+		for i := range msg {
+			if i > 0 {
+				time.Sleep(f.Pause)
+			}
+			if _, err := ss.conn.Write(msg[i : i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FaultSlowRead:
+		err := ss.send([]byte(resp))
+		time.Sleep(f.Pause)
+		return err
+	default:
+		return ss.send([]byte(resp))
+	}
+}
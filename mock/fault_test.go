@@ -0,0 +1,147 @@
+package mock
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+func TestFaultPlan(t *testing.T) {
+	t.Run("nth matching command should fail with internal error", func(t *testing.T) {
+		server, client := newFaultyMock(t, NewFaultPlan(1).Add(Fault{Command: "c", Filter: "faulty", Nth: 2, Action: FaultInternalError}))
+		defer client.Close()
+		f := client.GetFilter("faulty")
+		_, err := f.Check(bloomd.Key("foo"))
+		requireNoError(t, err)
+		_, err = client.GetFilter("other").Check(bloomd.Key("foo"))
+		requireNoError(t, err)
+		if _, err = f.Check(bloomd.Key("foo")); err == nil {
+			t.Fatal("second check expected to fail")
+		}
+		_, err = f.Check(bloomd.Key("foo"))
+		requireNoError(t, err)
+		if server.faultPlan().Applied(0) != 1 {
+			t.Fatal("fault expected to be applied once")
+		}
+	})
+
+	t.Run("dropped connection should require a new client", func(t *testing.T) {
+		_, client := newFaultyMock(t, NewFaultPlan(1).Add(Fault{Command: "m", Action: FaultDropConnection}))
+		defer client.Close()
+		_, err := client.GetFilter("faulty").Set(bloomd.Key("foo"))
+		requireNoError(t, err)
+		rr, err := client.GetFilter("faulty").MultiCheck(bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar")))
+		requireNoError(t, err)
+		_, err = rr.Next()
+		if err == nil {
+			t.Fatal("reading results expected to fail")
+		}
+		if berr, ok := err.(bloomd.Error); !ok || !berr.ShouldRetryWithNewClient {
+			t.Fatalf("error expected to require a new client but was %v", err)
+		}
+	})
+
+	t.Run("failed commands should not change the store", func(t *testing.T) {
+		plan := NewFaultPlan(1).
+			Add(Fault{Command: "s", Nth: 1, Action: FaultInternalError}).
+			Add(Fault{Command: "b", Nth: 1, Action: FaultDropConnection})
+		server, client := newFaultyMock(t, plan)
+		defer client.Close()
+		if _, err := client.GetFilter("faulty").Set(bloomd.Key("foo")); err == nil {
+			t.Fatal("set expected to fail")
+		}
+		rr, err := client.GetFilter("faulty").BulkSet(bloomd.NewArrayReader(bloomd.Key("bar")))
+		requireNoError(t, err)
+		if _, err = rr.Next(); err == nil {
+			t.Fatal("bulk set expected to fail")
+		}
+		if keys := server.Filters()["faulty"]; len(keys) != 0 {
+			t.Fatalf("no keys expected to be set but got %v", keys)
+		}
+	})
+
+	t.Run("garbage tokens should be reported as error", func(t *testing.T) {
+		_, client := newFaultyMock(t, NewFaultPlan(1).Add(Fault{Command: "m", Action: FaultGarbageTokens}))
+		defer client.Close()
+		rr, err := client.GetFilter("faulty").MultiCheck(bloomd.NewArrayReader(bloomd.Key("foo"), bloomd.Key("bar")))
+		requireNoError(t, err)
+		if _, err = rr.Next(); err == nil {
+			t.Fatal("garbage expected to be reported as error")
+		}
+	})
+
+	t.Run("truncated results should miss the last token", func(t *testing.T) {
+		conn, reader := newRawFaultyMock(t, NewFaultPlan(1).Add(Fault{Command: "m", Action: FaultTruncateResults}))
+		defer conn.Close()
+		if resp := rawCommand(t, conn, reader, "m faulty foo bar baz"); resp != "No No\n" {
+			t.Fatalf("unexpected response %q", resp)
+		}
+	})
+
+	t.Run("probabilistic faults should be applied to a part of commands", func(t *testing.T) {
+		plan := NewFaultPlan(1).Add(Fault{Command: "c", Probability: 0.5, Action: FaultInternalError})
+		conn, reader := newRawFaultyMock(t, plan)
+		defer conn.Close()
+		for i := 0; i < 100; i++ {
+			rawCommand(t, conn, reader, "c faulty foo")
+		}
+		if applied := plan.Applied(0); applied < 25 || applied > 75 {
+			t.Fatalf("fault expected to be applied around 50 times but was applied %d times", applied)
+		}
+	})
+
+	t.Run("latency and slow io should delay responses", func(t *testing.T) {
+		plan := NewFaultPlan(1).
+			Add(Fault{Command: "c", Nth: 1, Latency: 20 * time.Millisecond}).
+			Add(Fault{Command: "c", Nth: 2, Action: FaultSlowWrite, Pause: 10 * time.Millisecond}).
+			Add(Fault{Command: "c", Nth: 3, Action: FaultSlowRead, Pause: 20 * time.Millisecond})
+		conn, reader := newRawFaultyMock(t, plan)
+		defer conn.Close()
+		for i := 0; i < 4; i++ {
+			start := time.Now()
+			if resp := rawCommand(t, conn, reader, "c faulty foo"); resp != "No\n" {
+				t.Fatalf("unexpected response %q", resp)
+			}
+			// slow read delays the command following the affected one
+			if i != 2 && time.Since(start) < 15*time.Millisecond {
+				t.Fatalf("response %d expected to be delayed", i)
+			}
+		}
+	})
+}
+
+func newFaultyMock(t *testing.T, plan *FaultPlan) (*MockServer, *bloomd.Client) {
+	serverConn, clientConn := net.Pipe()
+	server := NewMockServer(serverConn)
+	go server.Serve()
+	client, err := bloomd.NewFromConn(clientConn)
+	requireNoError(t, err)
+	_, err = client.CreateFilter("faulty", 0, 0, true)
+	requireNoError(t, err)
+	_, err = client.CreateFilter("other", 0, 0, true)
+	requireNoError(t, err)
+	server.SetFaultPlan(plan)
+	return server, client
+}
+
+func newRawFaultyMock(t *testing.T, plan *FaultPlan) (net.Conn, *bufio.Reader) {
+	serverConn, clientConn := net.Pipe()
+	server := NewMockServer(serverConn)
+	go server.Serve()
+	reader := bufio.NewReader(clientConn)
+	rawCommand(t, clientConn, reader, "create faulty")
+	server.SetFaultPlan(plan)
+	return clientConn, reader
+}
+
+func rawCommand(t *testing.T, conn net.Conn, reader *bufio.Reader, cmd string) string {
+	t.Helper()
+	_, err := conn.Write([]byte(cmd + "\n"))
+	requireNoError(t, err)
+	resp, err := reader.ReadString('\n')
+	requireNoError(t, err)
+	return resp
+}
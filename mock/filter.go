@@ -0,0 +1,207 @@
+package mock
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Applifier/go-bloomd/utils/bloom"
+)
+
+// DefaultCapacity is the capacity used by create when none is provided
+var DefaultCapacity = 100000
+
+// DefaultProbability is the false positive probability used by create when none is provided
+var DefaultProbability = 0.0001
+
+// filter is a single filter of the mock server
+// keys are always stored exactly, but membership of probabilistic filters is answered by the bloom filter
+type filter struct {
+	name          string
+	capacity      int
+	prob          float64
+	inMemory      bool
+	probabilistic bool
+	proxied       bool
+	keys          map[string]bool
+	bloom         *bloom.Filter
+	// path is a snapshot file of the filter, empty if the store is not persistent
+	path string
+	// pagedSize is a size of the filter while its keys are paged out
+	pagedSize int
+
+	checks      uint64
+	checkHits   uint64
+	checkMisses uint64
+	sets        uint64
+	setHits     uint64
+	setMisses   uint64
+	pageIns     uint64
+	pageOuts    uint64
+}
+
+func newFilter(name string, capacity int, prob float64, inMemory bool) *filter {
+	return &filter{
+		name:     name,
+		capacity: capacity,
+		prob:     prob,
+		inMemory: inMemory,
+		keys:     make(map[string]bool),
+	}
+}
+
+func newBloomFilter(name string, capacity int, prob float64, inMemory bool) *filter {
+	f := newFilter(name, capacity, prob, inMemory)
+	f.probabilistic = true
+	f.bloom = bloom.New(capacity, prob)
+	return f
+}
+
+// set adds key to the filter and returns true if it was not present before
+// forced key is treated as a false positive and reported as present
+func (f *filter) set(key string, forced bool) bool {
+	f.sets++
+	if forced || f.contains(key) {
+		f.setMisses++
+		return false
+	}
+	f.keys[key] = true
+	if f.probabilistic {
+		f.bloom.Add([]byte(key))
+	}
+	f.setHits++
+	return true
+}
+
+// check returns true if key is present in the filter
+// forced key is treated as a false positive and reported as present
+func (f *filter) check(key string, forced bool) bool {
+	f.checks++
+	if forced || f.contains(key) {
+		f.checkHits++
+		return true
+	}
+	f.checkMisses++
+	return false
+}
+
+func (f *filter) contains(key string) bool {
+	if f.probabilistic {
+		return f.bloom.Test([]byte(key))
+	}
+	return f.keys[key]
+}
+
+func (f *filter) size() int {
+	if f.keys == nil {
+		return f.pagedSize
+	}
+	return len(f.keys)
+}
+
+// storage estimates amount of bytes the real bloomd would allocate for the filter
+func (f *filter) storage() uint64 {
+	m, _ := bloom.EstimateParameters(f.capacity, f.prob)
+	return (m + 7) / 8
+}
+
+// listLine formats filter the same way bloomd does in response to the list command
+func (f *filter) listLine() string {
+	return fmt.Sprintf("%s %f %d %d %d", f.name, f.prob, f.storage(), f.capacity, f.size())
+}
+
+// infoLines formats filter the same way bloomd does in response to the info command
+func (f *filter) infoLines() []string {
+	return []string{
+		"capacity " + strconv.Itoa(f.capacity),
+		"checks " + strconv.FormatUint(f.checks, 10),
+		"check_hits " + strconv.FormatUint(f.checkHits, 10),
+		"check_misses " + strconv.FormatUint(f.checkMisses, 10),
+		"in_memory " + boolToFlag(f.inMemory),
+		"page_ins " + strconv.FormatUint(f.pageIns, 10),
+		"page_outs " + strconv.FormatUint(f.pageOuts, 10),
+		"probability " + strconv.FormatFloat(f.prob, 'f', 6, 64),
+		"sets " + strconv.FormatUint(f.sets, 10),
+		"set_hits " + strconv.FormatUint(f.setHits, 10),
+		"set_misses " + strconv.FormatUint(f.setMisses, 10),
+		"size " + strconv.Itoa(f.size()),
+		"storage " + strconv.FormatUint(f.storage(), 10),
+	}
+}
+
+// persistent returns true if the filter is written to its snapshot file on flush and close
+func (f *filter) persistent() bool {
+	return f.path != "" && !f.inMemory
+}
+
+// flush writes the filter to its snapshot file, paged out filters are already there
+func (f *filter) flush() error {
+	if !f.persistent() || f.keys == nil {
+		return nil
+	}
+	return writeSnapshot(f.path, f.toSnapshot(f.keys))
+}
+
+// close pages the filter out, in memory filters can not be paged out
+// keys of persistent filters are released from memory until the filter is accessed again
+func (f *filter) close() error {
+	if f.inMemory || f.proxied {
+		return nil
+	}
+	if f.persistent() {
+		if err := f.flush(); err != nil {
+			return err
+		}
+		f.pagedSize = len(f.keys)
+		f.keys = nil
+		f.bloom = nil
+	}
+	f.proxied = true
+	f.pageOuts++
+	return nil
+}
+
+// touch pages the filter back in if it was closed
+func (f *filter) touch() error {
+	if !f.proxied {
+		return nil
+	}
+	if f.keys == nil {
+		snap, err := readSnapshot(f.path)
+		if err != nil {
+			return err
+		}
+		f.keys, f.bloom = snap.restoreKeys()
+	}
+	f.proxied = false
+	f.pageIns++
+	return nil
+}
+
+// currentKeys returns keys of the filter reading them from the snapshot file if the filter is paged out
+func (f *filter) currentKeys() (map[string]bool, error) {
+	if f.keys != nil {
+		return f.keys, nil
+	}
+	snap, err := readSnapshot(f.path)
+	if err != nil {
+		return nil, err
+	}
+	keys, _ := snap.restoreKeys()
+	return keys, nil
+}
+
+func (f *filter) snapshot() map[string]bool {
+	current, _ := f.currentKeys()
+	keys := make(map[string]bool, len(current))
+	for k, v := range current {
+		keys[k] = v
+	}
+	return keys
+}
+
+func boolToFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -1,7 +1,9 @@
 package mock
 
 import (
+	"fmt"
 	"net"
+	"strings"
 	"testing"
 
 	bloomd "github.com/Applifier/go-bloomd"
@@ -51,6 +53,171 @@ func TestMock(t *testing.T) {
 	}
 }
 
+func TestMockProtocol(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	server := NewMockServer(serverConn)
+	go server.Serve()
+
+	client, err := bloomd.NewFromConn(clientConn)
+	requireNoError(t, err)
+	defer client.Close()
+
+	t.Run("ping should be answered as unsupported command", func(t *testing.T) {
+		requireNoError(t, client.Ping())
+	})
+
+	t.Run("create should report existing filter", func(t *testing.T) {
+		_, err := client.CreateFilter("proto_a", 20000, 0.001, false)
+		requireNoError(t, err)
+		_, err = client.CreateFilter("proto_b", 0, 0, true)
+		requireNoError(t, err)
+		_, err = client.CreateFilter("other", 0, 0, true)
+		requireNoError(t, err)
+		if resp := server.handle("create proto_a"); resp != "Exists" {
+			t.Fatalf("expected Exists but got %s", resp)
+		}
+		if resp := server.handle("create proto_c prob=2"); resp != "Client Error: Bad arguments" {
+			t.Fatalf("expected bad arguments but got %s", resp)
+		}
+	})
+
+	t.Run("set should report if key is new", func(t *testing.T) {
+		f := client.GetFilter("proto_a")
+		isNew, err := f.Set(bloomd.Key("foo"))
+		requireNoError(t, err)
+		if !isNew {
+			t.Fatal("first set expected to return Yes")
+		}
+		isNew, err = f.Set(bloomd.Key("foo"))
+		requireNoError(t, err)
+		if isNew {
+			t.Fatal("second set expected to return No")
+		}
+		if resp := server.handle("b proto_a foo bar"); resp != "No Yes" {
+			t.Fatalf("expected No Yes but got %s", resp)
+		}
+	})
+
+	t.Run("list should return filters matching prefix", func(t *testing.T) {
+		if resp := server.handle("list proto_"); resp != "START\nproto_a 0.001000 35944 20000 2\nproto_b 0.000100 239627 100000 0\nEND" {
+			t.Fatalf("unexpected list response %q", resp)
+		}
+		filters, err := client.ListFilters()
+		requireNoError(t, err)
+		if len(filters) != 3 {
+			t.Fatalf("expected 3 filters but got %d", len(filters))
+		}
+	})
+
+	t.Run("info should return filter metadata", func(t *testing.T) {
+		_, err := client.GetFilter("proto_a").Check(bloomd.Key("foo"))
+		requireNoError(t, err)
+		info, err := client.GetFilter("proto_a").Info()
+		requireNoError(t, err)
+		expected := map[string]string{
+			"capacity":    "20000",
+			"checks":      "1",
+			"check_hits":  "1",
+			"in_memory":   "0",
+			"probability": "0.001000",
+			"sets":        "4",
+			"set_hits":    "2",
+			"set_misses":  "2",
+			"size":        "2",
+		}
+		for k, v := range expected {
+			if info[k] != v {
+				t.Errorf("expected %s to be %s but was %s", k, v, info[k])
+			}
+		}
+	})
+
+	t.Run("clear should require closed filter", func(t *testing.T) {
+		f := client.GetFilter("proto_a")
+		if err := f.Clear(); err == nil || !strings.Contains(err.Error(), "Filter is not proxied") {
+			t.Fatalf("expected not proxied error but got %v", err)
+		}
+		requireNoError(t, f.Flush())
+		requireNoError(t, f.Close())
+		requireNoError(t, f.Clear())
+		if _, ok := server.Filters()["proto_a"]; ok {
+			t.Fatal("cleared filter should be removed from the store")
+		}
+	})
+
+	t.Run("drop should remove filter", func(t *testing.T) {
+		f := client.GetFilter("proto_b")
+		requireNoError(t, f.Drop())
+		if err := f.Drop(); err == nil || !strings.Contains(err.Error(), "Filter does not exist") {
+			t.Fatalf("expected missing filter error but got %v", err)
+		}
+		if _, err := f.Check(bloomd.Key("foo")); err == nil || !strings.Contains(err.Error(), "Filter does not exist") {
+			t.Fatalf("expected missing filter error but got %v", err)
+		}
+		if _, err := f.Info(); err == nil {
+			t.Fatal("info of dropped filter expected to fail")
+		}
+	})
+}
+
+func TestMockFalsePositives(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+	server := NewMockServer(serverConn)
+	go server.Serve()
+
+	client, err := bloomd.NewFromConn(clientConn)
+	requireNoError(t, err)
+	defer client.Close()
+
+	t.Run("probabilistic filters should produce false positives", func(t *testing.T) {
+		server.SetProbabilistic(true)
+		defer server.SetProbabilistic(false)
+		f, err := client.CreateFilter("probabilistic", 100, 0.1, true)
+		requireNoError(t, err)
+		for i := 0; i < 100; i++ {
+			_, err := f.Set(bloomd.Key(fmt.Sprintf("key_%d", i)))
+			requireNoError(t, err)
+		}
+		positives := 0
+		for i := 0; i < 1000; i++ {
+			found, err := f.Check(bloomd.Key(fmt.Sprintf("other_%d", i)))
+			requireNoError(t, err)
+			if found {
+				positives++
+			}
+		}
+		if positives == 0 || positives > 300 {
+			t.Fatalf("false positives expected to be around 100 but were %d", positives)
+		}
+	})
+
+	t.Run("forced keys should be reported as present", func(t *testing.T) {
+		server.ForceFalsePositive("forced", "foo")
+		f, err := client.CreateFilter("forced", 0, 0, true)
+		requireNoError(t, err)
+		found, err := f.Check(bloomd.Key("foo"))
+		requireNoError(t, err)
+		if !found {
+			t.Fatal("forced key should be found")
+		}
+		isNew, err := f.Set(bloomd.Key("foo"))
+		requireNoError(t, err)
+		if isNew {
+			t.Fatal("forced key should not be reported as new")
+		}
+		if len(server.Filters()["forced"]) != 0 {
+			t.Fatal("forced key should not be stored")
+		}
+
+		server.ResetFalsePositives()
+		found, err = f.Check(bloomd.Key("foo"))
+		requireNoError(t, err)
+		if found {
+			t.Fatal("key should not be found after reset")
+		}
+	})
+}
+
 func requireNoError(tb testing.TB, err error) {
 	if err != nil {
 		tb.Fatal(err)
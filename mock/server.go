@@ -0,0 +1,204 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrServerClosed is returned by Server.Serve after a call to Shutdown
+var ErrServerClosed = errors.New("mock: server closed")
+
+var shutdownPollInterval = 10 * time.Millisecond
+
+// Server serves bloomd protocol for any number of listeners and connections sharing a single filter store
+type Server struct {
+	*dispatcher
+
+	lock      sync.Mutex
+	listeners []net.Listener
+	sessions  map[*session]struct{}
+	shutdown  bool
+	wg        sync.WaitGroup
+}
+
+// NewServer creates a server on top of the store, a new store is created if store is nil
+func NewServer(store *Store) *Server {
+	if store == nil {
+		store = NewStore()
+	}
+	return &Server{
+		dispatcher: newDispatcher(store),
+		sessions:   make(map[*session]struct{}),
+	}
+}
+
+// NewListener creates a server listening on addr and serves it in the background
+// addr is expected to be in the same format as for bloomd.NewFromAddr, e.g. tcp://127.0.0.1:0 or unix:///tmp/bloomd.sock
+func NewListener(addr string) (*Server, error) {
+	l, err := Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+	s := NewServer(nil)
+	s.trackListener(l)
+	go s.Serve(l)
+	return s, nil
+}
+
+// Listen opens a listener for addr in the format of bloomd.NewFromAddr
+func Listen(addr string) (net.Listener, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "tcp":
+		return net.Listen("tcp", u.Host)
+	case "unix":
+		return net.Listen("unix", u.Path)
+	case "":
+		return nil, fmt.Errorf("error: scheme is not presented in the url")
+	default:
+		return nil, fmt.Errorf("error: %s scheme is not supported", u.Scheme)
+	}
+}
+
+// Serve accepts connections on listener l and serves each of them in a separate goroutine
+// it always returns a non-nil error, after Shutdown the error is ErrServerClosed
+func (s *Server) Serve(l net.Listener) error {
+	if !s.trackListener(l) {
+		l.Close()
+		return ErrServerClosed
+	}
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isShutdown() {
+				return ErrServerClosed
+			}
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+		ss := newSession(conn, s.respond)
+		if !s.trackSession(ss) {
+			ss.close()
+			return ErrServerClosed
+		}
+		go func() {
+			defer s.untrackSession(ss)
+			ss.serve()
+		}()
+	}
+}
+
+// Addr returns address of the first listener in the format accepted by bloomd.NewFromAddr
+func (s *Server) Addr() string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if len(s.listeners) == 0 {
+		return ""
+	}
+	addr := s.listeners[0].Addr()
+	switch addr.Network() {
+	case "unix":
+		return "unix://" + addr.String()
+	default:
+		return addr.Network() + "://" + addr.String()
+	}
+}
+
+// Shutdown stops accepting new connections, waits for in flight commands to be answered and closes all connections
+// if ctx expires before all connections are closed, remaining connections are closed forcibly and ctx error is returned
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.lock.Lock()
+	s.shutdown = true
+	var err error
+	for _, l := range s.listeners {
+		if lerr := l.Close(); lerr != nil && err == nil {
+			err = lerr
+		}
+	}
+	s.lock.Unlock()
+
+	ticker := time.NewTicker(shutdownPollInterval)
+	defer ticker.Stop()
+	for {
+		if s.closeIdleSessions() {
+			s.wg.Wait()
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			s.closeAllSessions()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) trackListener(l net.Listener) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.shutdown {
+		return false
+	}
+	for _, tracked := range s.listeners {
+		if tracked == l {
+			return true
+		}
+	}
+	s.listeners = append(s.listeners, l)
+	return true
+}
+
+func (s *Server) trackSession(ss *session) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.shutdown {
+		return false
+	}
+	s.sessions[ss] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+func (s *Server) untrackSession(ss *session) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.sessions, ss)
+	s.wg.Done()
+}
+
+func (s *Server) isShutdown() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.shutdown
+}
+
+// closeIdleSessions closes sessions that are not processing a command, returns true if all sessions are closed
+func (s *Server) closeIdleSessions() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	allClosed := true
+	for ss := range s.sessions {
+		if !ss.closeIfIdle() {
+			allClosed = false
+		}
+	}
+	return allClosed
+}
+
+func (s *Server) closeAllSessions() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for ss := range s.sessions {
+		ss.close()
+	}
+}
@@ -0,0 +1,97 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	bloomd "github.com/Applifier/go-bloomd"
+)
+
+func TestServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bloomd_mock")
+	requireNoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, addr := range []string{"tcp://127.0.0.1:0", "unix://" + filepath.Join(dir, "bloomd.sock")} {
+		t.Run("Test address "+addr, func(t *testing.T) {
+			server, err := NewListener(addr)
+			requireNoError(t, err)
+
+			t.Run("clients should share filters", func(t *testing.T) {
+				c1, err := bloomd.NewFromAddr(server.Addr())
+				requireNoError(t, err)
+				defer c1.Close()
+				c2, err := bloomd.NewFromAddr(server.Addr())
+				requireNoError(t, err)
+				defer c2.Close()
+
+				f, err := c1.CreateFilter("shared", 0, 0, true)
+				requireNoError(t, err)
+				_, err = f.Set(bloomd.Key("foo"))
+				requireNoError(t, err)
+
+				found, err := c2.GetFilter("shared").Check(bloomd.Key("foo"))
+				requireNoError(t, err)
+				if !found {
+					t.Fatal("key set through one connection should be visible through another")
+				}
+			})
+
+			t.Run("pool should serve concurrent clients", func(t *testing.T) {
+				pool, err := bloomd.NewPoolFromAddr(2, 10, server.Addr())
+				requireNoError(t, err)
+				defer pool.Close()
+
+				var wg sync.WaitGroup
+				errs := make(chan error, 20)
+				for i := 0; i < 20; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						c, err := pool.Get()
+						if err != nil {
+							errs <- err
+							return
+						}
+						defer c.Close()
+						if _, err := c.GetFilter("shared").Set(bloomd.Key(fmt.Sprintf("key_%d", i))); err != nil {
+							errs <- err
+						}
+					}(i)
+				}
+				wg.Wait()
+				close(errs)
+				for err := range errs {
+					t.Fatal(err)
+				}
+				if len(server.Filters()["shared"]) != 21 {
+					t.Fatalf("expected 21 keys but got %d", len(server.Filters()["shared"]))
+				}
+			})
+
+			t.Run("shutdown should close connections and listener", func(t *testing.T) {
+				c, err := bloomd.NewFromAddr(server.Addr())
+				requireNoError(t, err)
+				defer c.Close()
+				requireNoError(t, c.Ping())
+
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				requireNoError(t, server.Shutdown(ctx))
+
+				if err := c.Ping(); err == nil {
+					t.Fatal("ping expected to fail after shutdown")
+				}
+				if _, err := bloomd.NewFromAddr(server.Addr()); err == nil {
+					t.Fatal("new connections expected to be refused after shutdown")
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package mock
+
+import (
+	"encoding/gob"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Applifier/go-bloomd/utils/bloom"
+)
+
+const snapshotFilePrefix = "bloomd."
+
+// filterSnapshot is a serializable state of a filter
+type filterSnapshot struct {
+	Name          string
+	Capacity      int
+	Prob          float64
+	InMemory      bool
+	Probabilistic bool
+	Keys          []string
+}
+
+// NewPersistentStore creates a store keeping filters that are not in memory in dir
+// filters are written to dir on flush and close, and filters found in dir are available in the store closed
+// so they are paged in on first access like in bloomd after restart
+func NewPersistentStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := NewStore()
+	s.dir = dir
+	for _, file := range files {
+		if file.IsDir() || !strings.HasPrefix(file.Name(), snapshotFilePrefix) {
+			continue
+		}
+		f, err := discoverFilter(filepath.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s.filters[f.name] = f
+	}
+	return s, nil
+}
+
+// Flush writes all persistent filters into the snapshot directory
+func (s *Store) Flush() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, f := range s.filters {
+		if err := f.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotPath returns path of the snapshot file for the filter in the store directory, empty if store is not persistent
+func (s *Store) snapshotPath(name string) string {
+	if s.dir == "" {
+		return ""
+	}
+	return snapshotPath(s.dir, name)
+}
+
+func snapshotPath(dir string, name string) string {
+	return filepath.Join(dir, snapshotFilePrefix+url.PathEscape(name))
+}
+
+// discoverFilter creates a closed filter from the snapshot file, keys are read on the first access
+func discoverFilter(path string) (*filter, error) {
+	snap, err := readSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	f := newFilter(snap.Name, snap.Capacity, snap.Prob, snap.InMemory)
+	f.probabilistic = snap.Probabilistic
+	f.path = path
+	f.proxied = true
+	f.keys = nil
+	f.pagedSize = len(snap.Keys)
+	return f, nil
+}
+
+// writeSnapshot writes snapshot to a temporary file and renames it, so a snapshot is never partially written
+func writeSnapshot(path string, snap filterSnapshot) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(snap); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func readSnapshot(path string) (filterSnapshot, error) {
+	var snap filterSnapshot
+	file, err := os.Open(path)
+	if err != nil {
+		return snap, err
+	}
+	defer file.Close()
+	err = gob.NewDecoder(file).Decode(&snap)
+	return snap, err
+}
+
+func (f *filter) toSnapshot(keys map[string]bool) filterSnapshot {
+	snapKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		snapKeys = append(snapKeys, k)
+	}
+	return filterSnapshot{
+		Name:          f.name,
+		Capacity:      f.capacity,
+		Prob:          f.prob,
+		InMemory:      f.inMemory,
+		Probabilistic: f.probabilistic,
+		Keys:          snapKeys,
+	}
+}
+
+// restoreKeys rebuilds keys of the snapshot, bloom filter is nil for exact filters
+func (snap filterSnapshot) restoreKeys() (map[string]bool, *bloom.Filter) {
+	keys := make(map[string]bool, len(snap.Keys))
+	var b *bloom.Filter
+	if snap.Probabilistic {
+		b = bloom.New(snap.Capacity, snap.Prob)
+	}
+	for _, k := range snap.Keys {
+		keys[k] = true
+		if b != nil {
+			b.Add([]byte(k))
+		}
+	}
+	return keys, b
+}
@@ -0,0 +1,85 @@
+package mock
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPersistentStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bloomd_persistent")
+	requireNoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := NewPersistentStore(dir)
+	requireNoError(t, err)
+	store.handle("create persistent")
+	store.handle("create cleared")
+	store.handle("create dropped")
+	store.handle("b persistent foo bar")
+	store.handle("s cleared foo")
+
+	t.Run("close should page filter out", func(t *testing.T) {
+		expectResponse(t, store, "close persistent", "Done")
+		if store.filters["persistent"].keys != nil {
+			t.Fatal("keys of closed filter should be released")
+		}
+		expectInfo(t, store, "persistent", "page_outs 1", "page_ins 0", "size 2")
+		if keys := store.Filters()["persistent"]; !keys["foo"] {
+			t.Fatalf("keys of closed filter should be readable but were %v", keys)
+		}
+	})
+
+	t.Run("access should page filter in", func(t *testing.T) {
+		expectResponse(t, store, "c persistent foo", "Yes")
+		expectInfo(t, store, "persistent", "page_outs 1", "page_ins 1")
+	})
+
+	t.Run("drop should remove data and clear should keep it", func(t *testing.T) {
+		expectResponse(t, store, "drop dropped", "Done")
+		expectResponse(t, store, "close cleared", "Done")
+		expectResponse(t, store, "clear cleared", "Done")
+		expectResponse(t, store, "list cleared", "START\nEND")
+		expectResponse(t, store, "create cleared", "Done")
+		expectResponse(t, store, "c cleared foo", "Yes")
+		expectResponse(t, store, "flush", "Done")
+	})
+
+	t.Run("filters should be restored closed after restart", func(t *testing.T) {
+		store.handle("s persistent baz")
+		expectResponse(t, store, "flush persistent", "Done")
+
+		restarted, err := NewPersistentStore(dir)
+		requireNoError(t, err)
+		expectResponse(t, restarted, "list", "START\ncleared 0.000100 239627 100000 1\npersistent 0.000100 239627 100000 3\nEND")
+		expectResponse(t, restarted, "m persistent foo bar baz biz", "Yes Yes Yes No")
+		expectInfo(t, restarted, "persistent", "page_ins 1", "checks 4")
+	})
+}
+
+func containsLine(s string, line string) bool {
+	for _, l := range strings.Split(s, "\n") {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+func expectResponse(t *testing.T, store *Store, cmd string, expected string) {
+	t.Helper()
+	if resp := store.handle(cmd); resp != expected {
+		t.Fatalf("%q expected to return %q but returned %q", cmd, expected, resp)
+	}
+}
+
+func expectInfo(t *testing.T, store *Store, name string, lines ...string) {
+	t.Helper()
+	info := store.handle("info " + name)
+	for _, line := range lines {
+		if !containsLine(info, line) {
+			t.Errorf("info expected to contain %q but was %q", line, info)
+		}
+	}
+}
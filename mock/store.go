@@ -0,0 +1,361 @@
+package mock
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Responses of the bloomd protocol
+const (
+	respDone           = "Done"
+	respExists         = "Exists"
+	respYes            = "Yes"
+	respNo             = "No"
+	respStart          = "START"
+	respEnd            = "END"
+	respNotExist       = "Filter does not exist"
+	respNotProxied     = "Filter is not proxied. Close it first."
+	respCmdNotSupport  = "Client Error: Command not supported"
+	respBadArgs        = "Client Error: Bad arguments"
+	respUnexpectedArgs = "Client Error: Unexpected arguments"
+	respFilterKeyReq   = "Client Error: Must provide filter name and key"
+	respFilterReq      = "Client Error: Must provide filter name"
+	respBadFilterName  = "Client Error: Bad filter name"
+	respInternalError  = "Internal Error"
+)
+
+const maxFilterNameLength = 200
+
+// Store keeps filters of the mock server and implements bloomd protocol on top of them
+type Store struct {
+	lock           sync.Mutex
+	filters        map[string]*filter
+	dir            string
+	probabilistic  bool
+	falsePositives map[string]map[string]bool
+}
+
+// NewStore creates an empty filter store
+func NewStore() *Store {
+	return &Store{
+		filters:        make(map[string]*filter),
+		falsePositives: make(map[string]map[string]bool),
+	}
+}
+
+// SetProbabilistic switches filters created afterwards to be backed by a real bloom filter
+// honoring capacity and prob of the create command, so false positives can occur as in bloomd
+func (s *Store) SetProbabilistic(probabilistic bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.probabilistic = probabilistic
+}
+
+// ForceFalsePositive makes keys to be reported as present in filter even if they were never set
+// it applies to the filter with such name regardless of whether it exists yet
+func (s *Store) ForceFalsePositive(filterName string, keys ...string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	forced, ok := s.falsePositives[filterName]
+	if !ok {
+		forced = make(map[string]bool, len(keys))
+		s.falsePositives[filterName] = forced
+	}
+	for _, key := range keys {
+		forced[key] = true
+	}
+}
+
+// ResetFalsePositives removes all keys forced by ForceFalsePositive
+func (s *Store) ResetFalsePositives() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.falsePositives = make(map[string]map[string]bool)
+}
+
+// Filters returns a copy of keys stored per filter
+func (s *Store) Filters() map[string]map[string]bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	filters := make(map[string]map[string]bool, len(s.filters))
+	for name, f := range s.filters {
+		filters[name] = f.snapshot()
+	}
+	return filters
+}
+
+func (s *Store) handle(cmdString string) string {
+	tokens := strings.Fields(cmdString)
+	if len(tokens) == 0 {
+		return respCmdNotSupport
+	}
+	cmd := tokens[0]
+	args := tokens[1:]
+	switch cmd {
+	case "create":
+		return s.create(args)
+	case "list":
+		return s.list(args)
+	case "drop":
+		return s.withFilterName(args, s.drop)
+	case "close":
+		return s.withFilterName(args, s.close)
+	case "clear":
+		return s.withFilterName(args, s.clear)
+	case "flush":
+		if len(args) == 0 {
+			return s.flushAll()
+		}
+		return s.withFilterName(args, s.flush)
+	case "info":
+		return s.withFilterName(args, s.info)
+	case "b", "bulk":
+		return s.withFilterAndKeys(args, false, s.bulkSet)
+	case "s", "set":
+		return s.withFilterAndKeys(args, true, s.bulkSet)
+	case "m", "multi":
+		return s.withFilterAndKeys(args, false, s.multiCheck)
+	case "c", "check":
+		return s.withFilterAndKeys(args, true, s.multiCheck)
+	default:
+		return respCmdNotSupport
+	}
+}
+
+func (s *Store) withFilterName(args []string, op func(name string) string) string {
+	switch {
+	case len(args) == 0:
+		return respFilterReq
+	case len(args) > 1:
+		return respUnexpectedArgs
+	}
+	return op(args[0])
+}
+
+func (s *Store) withFilterAndKeys(args []string, single bool, op func(name string, keys []string) string) string {
+	switch {
+	case len(args) < 2:
+		return respFilterKeyReq
+	case single && len(args) > 2:
+		return respUnexpectedArgs
+	}
+	return op(args[0], args[1:])
+}
+
+func (s *Store) create(args []string) string {
+	if len(args) == 0 {
+		return respFilterReq
+	}
+	name := args[0]
+	if len(name) > maxFilterNameLength {
+		return respBadFilterName
+	}
+	capacity := DefaultCapacity
+	prob := DefaultProbability
+	inMemory := false
+	for _, param := range args[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return respBadArgs
+		}
+		var err error
+		switch kv[0] {
+		case "capacity":
+			capacity, err = strconv.Atoi(kv[1])
+			if err != nil || capacity < 1 {
+				return respBadArgs
+			}
+		case "prob":
+			prob, err = strconv.ParseFloat(kv[1], 64)
+			if err != nil || prob <= 0 || prob >= 1 {
+				return respBadArgs
+			}
+		case "in_memory":
+			switch kv[1] {
+			case "0":
+				inMemory = false
+			case "1":
+				inMemory = true
+			default:
+				return respBadArgs
+			}
+		default:
+			return respBadArgs
+		}
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, present := s.filters[name]; present {
+		return respExists
+	}
+	path := s.snapshotPath(name)
+	if path != "" && !inMemory {
+		// like bloomd, filter is recreated from the data left on disk by clear
+		if _, err := os.Stat(path); err == nil {
+			f, err := discoverFilter(path)
+			if err != nil {
+				return respInternalError
+			}
+			s.filters[name] = f
+			return respDone
+		}
+	}
+	var f *filter
+	if s.probabilistic {
+		f = newBloomFilter(name, capacity, prob, inMemory)
+	} else {
+		f = newFilter(name, capacity, prob, inMemory)
+	}
+	f.path = path
+	s.filters[name] = f
+	return respDone
+}
+
+func (s *Store) list(args []string) string {
+	if len(args) > 1 {
+		return respUnexpectedArgs
+	}
+	prefix := ""
+	if len(args) == 1 {
+		prefix = args[0]
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	names := make([]string, 0, len(s.filters))
+	for name := range s.filters {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	lines := make([]string, 0, len(names)+2)
+	lines = append(lines, respStart)
+	for _, name := range names {
+		lines = append(lines, s.filters[name].listLine())
+	}
+	lines = append(lines, respEnd)
+	return strings.Join(lines, "\n")
+}
+
+func (s *Store) drop(name string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, present := s.filters[name]
+	if !present {
+		return respNotExist
+	}
+	delete(s.filters, name)
+	if f.path != "" {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return respInternalError
+		}
+	}
+	return respDone
+}
+
+func (s *Store) close(name string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, present := s.filters[name]
+	if !present {
+		return respNotExist
+	}
+	if err := f.close(); err != nil {
+		return respInternalError
+	}
+	return respDone
+}
+
+// clear removes filter from the store, like bloomd it is allowed only for closed filters and keeps data on disk
+func (s *Store) clear(name string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, present := s.filters[name]
+	if !present {
+		return respNotExist
+	}
+	if !f.proxied {
+		return respNotProxied
+	}
+	delete(s.filters, name)
+	return respDone
+}
+
+func (s *Store) flush(name string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, present := s.filters[name]
+	if !present {
+		return respNotExist
+	}
+	if err := f.flush(); err != nil {
+		return respInternalError
+	}
+	return respDone
+}
+
+func (s *Store) flushAll() string {
+	if err := s.Flush(); err != nil {
+		return respInternalError
+	}
+	return respDone
+}
+
+func (s *Store) info(name string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, present := s.filters[name]
+	if !present {
+		return respNotExist
+	}
+	lines := append([]string{respStart}, f.infoLines()...)
+	return strings.Join(append(lines, respEnd), "\n")
+}
+
+func (s *Store) bulkSet(name string, keys []string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, present := s.filters[name]
+	if !present {
+		return respNotExist
+	}
+	if err := f.touch(); err != nil {
+		return respInternalError
+	}
+	forced := s.falsePositives[name]
+	responses := make([]string, len(keys))
+	for i, key := range keys {
+		responses[i] = yesNo(f.set(key, forced[key]))
+	}
+	return strings.Join(responses, " ")
+}
+
+func (s *Store) multiCheck(name string, keys []string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	f, present := s.filters[name]
+	if !present {
+		return respNotExist
+	}
+	if err := f.touch(); err != nil {
+		return respInternalError
+	}
+	forced := s.falsePositives[name]
+	responses := make([]string, len(keys))
+	for i, key := range keys {
+		responses[i] = yesNo(f.check(key, forced[key]))
+	}
+	return strings.Join(responses, " ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return respYes
+	}
+	return respNo
+}
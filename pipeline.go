@@ -0,0 +1,113 @@
+package bloomd
+
+// Pipeline queues commands of a client and sends them all before reading any result
+// so a number of commands costs a single round trip
+// pipelined commands do not use the client cache
+type Pipeline struct {
+	client *Client
+	ops    []pipelineOp
+}
+
+type pipelineOp struct {
+	op         string
+	filterName string
+	keys       KeyReader
+	key        Key
+}
+
+// Pipeline creates an empty pipeline of the client
+func (cli *Client) Pipeline() *Pipeline {
+	return &Pipeline{
+		client: cli,
+	}
+}
+
+// Len returns amount of queued commands
+func (p *Pipeline) Len() int {
+	return len(p.ops)
+}
+
+// BulkSet queues setting of keys to the filter
+func (p *Pipeline) BulkSet(filterName string, keys KeyReader) *Pipeline {
+	return p.add(pipelineOp{op: "b", filterName: filterName, keys: keys})
+}
+
+// MultiCheck queues checking of keys in the filter
+func (p *Pipeline) MultiCheck(filterName string, keys KeyReader) *Pipeline {
+	return p.add(pipelineOp{op: "m", filterName: filterName, keys: keys})
+}
+
+// Set queues setting of a single key to the filter
+func (p *Pipeline) Set(filterName string, key Key) *Pipeline {
+	return p.add(pipelineOp{op: "s", filterName: filterName, key: key})
+}
+
+// Check queues checking of a single key in the filter
+func (p *Pipeline) Check(filterName string, key Key) *Pipeline {
+	return p.add(pipelineOp{op: "c", filterName: filterName, key: key})
+}
+
+func (p *Pipeline) add(op pipelineOp) *Pipeline {
+	p.ops = append(p.ops, op)
+	return p
+}
+
+// Exec sends queued commands and calls handle with results of each command in the order they were queued
+// commands are written concurrently with reading of results, so large pipelines do not block on full socket buffers
+// results which are not read by handle are skipped, if handle returns error the rest of results is skipped too
+// and the first error is returned after all results are received
+// the pipeline is empty after Exec and can be reused
+func (p *Pipeline) Exec(handle func(i int, results ResultReader) error) error {
+	ops := p.ops
+	p.ops = nil
+	if len(ops) == 0 {
+		return nil
+	}
+	cli := p.client
+	counts := make(chan int, len(ops))
+	writeErr := make(chan error, 1)
+	go func() {
+		defer close(counts)
+		for _, op := range ops {
+			f := cli.GetFilter(op.filterName)
+			if op.keys != nil {
+				counts <- f.writeBatchOp(op.op, op.keys)
+			} else {
+				f.writeSingleOp(op.op, op.key)
+				counts <- 1
+			}
+		}
+		writeErr <- cli.writer.Flush()
+	}()
+
+	var handleErr error
+	i := 0
+	for count := range counts {
+		if count == 0 {
+			// server responds with a single error line to batch commands without keys
+			if cli.err == nil {
+				resp, err := cli.read()
+				if err == nil && handleErr == nil {
+					handleErr = Error{Message: "invalid response from server: " + resp}
+				}
+			}
+			i++
+			continue
+		}
+		results := cli.GetFilter(ops[i].filterName).readerFor(count)
+		if handleErr == nil && cli.err == nil {
+			handleErr = handle(i, results)
+		}
+		if cli.err == nil {
+			results.Close()
+		}
+		i++
+	}
+	if err := <-writeErr; err != nil {
+		return cli.handleWriteError(err)
+	}
+	if cli.err != nil {
+		return cli.err
+	}
+	return handleErr
+}
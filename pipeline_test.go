@@ -0,0 +1,155 @@
+package bloomd
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/Applifier/go-bloomd/utils/testutils"
+)
+
+func TestPipeline(t *testing.T) {
+	testutils.TestForAllAddrs(t, func(url *url.URL, t *testing.T) {
+		c := createClientFromURL(t, url)
+		defer closeClient(t, c)
+		names := make([]string, 3)
+		for i := range names {
+			f, err := c.CreateFilter(fmt.Sprintf("pipeline_%d_%s", i, url.Scheme), 0, 0, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Drop()
+			names[i] = f.Name
+		}
+
+		t.Run("results should be handled in order of commands", func(t *testing.T) {
+			p := c.Pipeline()
+			for i, name := range names {
+				p.Set(name, Key(fmt.Sprintf("key_%d", i)))
+			}
+			p.BulkSet(names[0], NewArrayReader(Key("foo"), Key("bar")))
+			if p.Len() != 4 {
+				t.Fatalf("4 commands should be queued but %d are", p.Len())
+			}
+			if err := p.Exec(func(i int, results ResultReader) error {
+				if !next(t, results) {
+					t.Errorf("key of command %d should be new", i)
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, name := range names {
+				p.MultiCheck(name, NewArrayReader(Key("key_0"), Key("key_1"), Key("key_2")))
+				p.Check(name, Key(fmt.Sprintf("key_%d", i)))
+			}
+			handled := 0
+			if err := p.Exec(func(i int, results ResultReader) error {
+				handled++
+				if i%2 == 1 {
+					if !next(t, results) {
+						t.Errorf("key should be found by command %d", i)
+					}
+					return nil
+				}
+				for j := 0; j < 3; j++ {
+					if next(t, results) != (j == i/2) {
+						t.Errorf("wrong result %d of command %d", j, i)
+					}
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if handled != 6 {
+				t.Errorf("6 commands should be handled but %d were", handled)
+			}
+		})
+
+		t.Run("unread results should be skipped", func(t *testing.T) {
+			p := c.Pipeline()
+			p.MultiCheck(names[0], NewArrayReader(Key("foo"), Key("bar"))).Check(names[1], Key("key_1"))
+			var found bool
+			if err := p.Exec(func(i int, results ResultReader) error {
+				if i == 1 {
+					found = next(t, results)
+				}
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Error("key_1 should be found")
+			}
+		})
+
+		t.Run("errors should be reported after all results are received", func(t *testing.T) {
+			p := c.Pipeline()
+			p.MultiCheck("pipeline_missing_"+url.Scheme, NewArrayReader(Key("foo"), Key("bar")))
+			p.MultiCheck(names[0], NewArrayReader())
+			p.Check(names[0], Key("foo"))
+			err := p.Exec(func(i int, results ResultReader) error {
+				_, err := results.Next()
+				return err
+			})
+			if err == nil {
+				t.Fatal("error should be returned")
+			}
+			if err := c.Ping(); err != nil {
+				t.Fatalf("client should stay usable but %v", err)
+			}
+		})
+	})
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	for _, addr := range testutils.BloomdAddrs() {
+		url := testutils.ParseURL(b, addr)
+		b.Run("Test address "+addr, func(b *testing.B) {
+			c := createClientFromURL(b, url)
+			defer closeClient(b, c)
+			keys := make([]Key, 100)
+			for i := range keys {
+				keys[i] = Key(fmt.Sprintf("key_%d", i))
+			}
+			for _, filters := range []int{10, 50, 180} {
+				names := make([]string, filters)
+				for i := range names {
+					f, err := c.CreateFilter(fmt.Sprintf("bench_pipeline_%d_%d_%s", filters, i, url.Scheme), 0, 0, true)
+					if err != nil {
+						b.Fatal(err)
+					}
+					defer f.Drop()
+					names[i] = f.Name
+				}
+
+				b.Run(fmt.Sprintf("Sequential-f%d", filters), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						for _, name := range names {
+							results, err := c.GetFilter(name).MultiCheck(NewArrayReader(keys...))
+							if err != nil {
+								b.Fatal(err)
+							}
+							results.Close()
+						}
+					}
+				})
+
+				b.Run(fmt.Sprintf("Pipelined-f%d", filters), func(b *testing.B) {
+					p := c.Pipeline()
+					for i := 0; i < b.N; i++ {
+						for _, name := range names {
+							p.MultiCheck(name, NewArrayReader(keys...))
+						}
+						if err := p.Exec(func(i int, results ResultReader) error {
+							return nil
+						}); err != nil {
+							b.Fatal(err)
+						}
+					}
+				})
+			}
+		})
+	}
+}
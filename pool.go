@@ -1,6 +1,7 @@
 package bloomd
 
 import (
+	"context"
 	"net"
 	"net/url"
 	"sync"
@@ -15,6 +16,7 @@ type Factory func() (net.Conn, error)
 type Pool struct {
 	connPool         pool.Pool
 	clientStructPool *sync.Pool
+	cache            *Cache
 }
 
 // NewPoolFromAddr return a new pool of client for addr
@@ -64,10 +66,37 @@ func (p *Pool) Get() (*Client, error) {
 
 	cli := p.clientStructPool.Get().(*Client)
 	cli.reset(conn)
+	cli.cache = p.cache
 
 	return cli, nil
 }
 
+// GetContext returns a new client from the pool with deadline of ctx applied to its connection
+// Client is returned to pool by calling client.Close()
+func (p *Pool) GetContext(ctx context.Context) (*Client, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cli, err := p.Get()
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := cli.SetDeadline(deadline); err != nil {
+			cli.err = err
+			cli.Close()
+			return nil, err
+		}
+	}
+	return cli, nil
+}
+
+// SetCache makes all clients returned by the pool share the cache of present keys
+// it should be called before the pool is used, nil disables caching
+func (p *Pool) SetCache(cache *Cache) {
+	p.cache = cache
+}
+
 // Close closes pool
 func (p *Pool) Close() {
 	p.connPool.Close()
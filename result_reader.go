@@ -5,12 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/Applifier/go-bloomd/utils/mathutils"
 )
 
 var ErrCursorOverLength = errors.New("resultReader: cursor is over length")
 
+// ErrFilterNotExist is returned by results and responses of operations on a filter that does not exist
+var ErrFilterNotExist = errors.New("Filter does not exist")
+
 var yesToken = []byte("Yes")
 var noToken = []byte("No")
 
@@ -55,11 +59,15 @@ func (r *resultReader) readResultFor(first bool, delimeter byte) (bool, error) {
 		return false, err
 	}
 	if first && !isYes(s) && !isNo(s) { // if it is not expected token it is an error
-		str, err := r.readToEnd()
+		head := string(s) // s is invalidated by the next read
+		rest, err := r.readRest()
 		if err != nil {
 			return false, r.client.handleReadError(err)
 		}
-		return false, fmt.Errorf("%s%c%s", s, itemDelimeter, str)
+		if strings.TrimSpace(head+string(itemDelimeter)+rest) == ErrFilterNotExist.Error() {
+			return false, ErrFilterNotExist
+		}
+		return false, fmt.Errorf("%s%c", head, itemDelimeter)
 	}
 	return isYes(s), nil
 }
@@ -130,6 +138,19 @@ func (r *resultReader) readToEnd() ([]byte, error) {
 	return emptySlice, nil
 }
 
+// readRest reads the rest of the response line without delimeter, it is used to recognize error messages
+func (r *resultReader) readRest() (string, error) {
+	if r.cursor >= r.length {
+		return "", nil
+	}
+	s, err := r.client.reader.ReadSlice(cmdDelimeter)
+	r.cursor = r.length
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	return strings.TrimSuffix(string(s), string(cmdDelimeter)), nil
+}
+
 func (r *resultReader) Close() error {
 	// just read everything left
 	_, err := r.readToEnd()
@@ -0,0 +1,97 @@
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Filter is a classic bloom filter with a fixed capacity and false positive probability
+type Filter struct {
+	bits     []uint64
+	m        uint64
+	k        uint64
+	capacity int
+	prob     float64
+	count    int
+}
+
+// New creates a filter sized to hold capacity keys with prob probability of false positives
+func New(capacity int, prob float64) *Filter {
+	m, k := EstimateParameters(capacity, prob)
+	return &Filter{
+		bits:     make([]uint64, (m+63)/64),
+		m:        m,
+		k:        k,
+		capacity: capacity,
+		prob:     prob,
+	}
+}
+
+// EstimateParameters returns amount of bits and hash functions required for the capacity and probability
+func EstimateParameters(capacity int, prob float64) (m uint64, k uint64) {
+	if capacity < 1 {
+		capacity = 1
+	}
+	bits := math.Ceil(-float64(capacity) * math.Log(prob) / (math.Ln2 * math.Ln2))
+	m = uint64(math.Max(bits, 64))
+	k = uint64(math.Max(math.Round(bits/float64(capacity)*math.Ln2), 1))
+	return m, k
+}
+
+// Add adds key to the filter, it returns false if the key is (probably) present already
+func (f *Filter) Add(key []byte) bool {
+	h1, h2 := hashes(key)
+	added := false
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		word, mask := pos/64, uint64(1)<<(pos%64)
+		if f.bits[word]&mask == 0 {
+			f.bits[word] |= mask
+			added = true
+		}
+	}
+	if added {
+		f.count++
+	}
+	return added
+}
+
+// Test returns true if key is (probably) present in the filter
+func (f *Filter) Test(key []byte) bool {
+	h1, h2 := hashes(key)
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos/64]&(uint64(1)<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns amount of keys added to the filter
+func (f *Filter) Count() int {
+	return f.count
+}
+
+// Capacity returns amount of keys the filter was sized for
+func (f *Filter) Capacity() int {
+	return f.capacity
+}
+
+// Probability returns false positive probability the filter was sized for
+func (f *Filter) Probability() float64 {
+	return f.prob
+}
+
+// Bytes returns amount of bytes used by the filter bits
+func (f *Filter) Bytes() uint64 {
+	return uint64(len(f.bits)) * 8
+}
+
+// hashes derives two independent hashes from 64 bit FNV-1a, the rest are simulated by double hashing
+func hashes(key []byte) (uint64, uint64) {
+	h := fnv.New64a()
+	h.Write(key)
+	sum := h.Sum64()
+	return sum & math.MaxUint32, sum>>32 | 1
+}
@@ -0,0 +1,49 @@
+package bloom
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	t.Run("Add should report new keys", func(t *testing.T) {
+		f := New(100, 0.01)
+		if !f.Add([]byte("foo")) {
+			t.Error("first add should return true")
+		}
+		if f.Add([]byte("foo")) {
+			t.Error("second add should return false")
+		}
+		if f.Count() != 1 {
+			t.Errorf("Count should be 1 but was %d", f.Count())
+		}
+	})
+
+	t.Run("Test should find added keys", func(t *testing.T) {
+		f := New(1000, 0.01)
+		for i := 0; i < 1000; i++ {
+			f.Add([]byte(fmt.Sprintf("key_%d", i)))
+		}
+		for i := 0; i < 1000; i++ {
+			if !f.Test([]byte(fmt.Sprintf("key_%d", i))) {
+				t.Fatalf("key_%d should be found", i)
+			}
+		}
+	})
+
+	t.Run("false positive rate should be close to requested probability", func(t *testing.T) {
+		f := New(10000, 0.01)
+		for i := 0; i < 10000; i++ {
+			f.Add([]byte(fmt.Sprintf("key_%d", i)))
+		}
+		positives := 0
+		for i := 0; i < 10000; i++ {
+			if f.Test([]byte(fmt.Sprintf("other_%d", i))) {
+				positives++
+			}
+		}
+		if positives == 0 || positives > 300 {
+			t.Errorf("false positives expected to be around 100 but were %d", positives)
+		}
+	})
+}
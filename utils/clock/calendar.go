@@ -0,0 +1,135 @@
+package clock
+
+import (
+	"time"
+
+	"github.com/Applifier/go-bloomd/utils/period"
+)
+
+// Calendar computes unit numbers in a single location with a configurable first day of week
+// days, weeks and months start at local midnight of the location
+type Calendar struct {
+	location  *time.Location
+	weekStart time.Weekday
+}
+
+// UTCCalendar computes units in UTC with weeks starting on Thursday as the global functions DayNumOf and WeekNumOf do
+var UTCCalendar = NewCalendar(time.UTC, time.Thursday)
+
+// NewCalendar creates a calendar for location, nil location means UTC
+// weekStart is the first day of week, e.g. time.Monday for ISO weeks
+func NewCalendar(location *time.Location, weekStart time.Weekday) *Calendar {
+	if location == nil {
+		location = time.UTC
+	}
+	return &Calendar{
+		location:  location,
+		weekStart: weekStart,
+	}
+}
+
+// Location returns location of the calendar
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+// WeekStart returns the first day of week
+func (c *Calendar) WeekStart() time.Weekday {
+	return c.weekStart
+}
+
+// NumOf returns number of the unit containing t
+// duration units do not depend on location
+func (c *Calendar) NumOf(unit Unit, t time.Time) (UnitNum, error) {
+	numOf, err := c.NumOfFunc(unit)
+	if err != nil {
+		return UnitZero, err
+	}
+	return numOf(t), nil
+}
+
+// NumOfFunc returns function computing number of the unit containing a time
+func (c *Calendar) NumOfFunc(unit Unit) (func(t time.Time) UnitNum, error) {
+	switch unit {
+	case MonthUnit:
+		return c.MonthNumOf, nil
+	case WeekUnit:
+		return c.WeekNumOf, nil
+	case DayUnit:
+		return c.DayNumOf, nil
+	case HourUnit:
+		return c.HourNumOf, nil
+	case MinuteUnit:
+		return c.MinuteNumOf, nil
+	}
+	buckets, err := ParseDurationUnit(unit)
+	if err != nil {
+		return nil, err
+	}
+	return buckets.NumOf, nil
+}
+
+// RangeOf returns time range [from, to) of the unit number
+func (c *Calendar) RangeOf(unit Unit, num UnitNum) (from time.Time, to time.Time, err error) {
+	n := int(num) - 1
+	switch unit {
+	case MonthUnit:
+		return c.date(0, n, 0, 0, 0), c.date(0, n+1, 0, 0, 0), nil
+	case WeekUnit:
+		shift := (int(time.Thursday) - int(c.weekStart) + period.DaysInWeek) % period.DaysInWeek
+		days := n*period.DaysInWeek - shift
+		return c.date(0, 0, days, 0, 0), c.date(0, 0, days+period.DaysInWeek, 0, 0), nil
+	case DayUnit:
+		return c.date(0, 0, n, 0, 0), c.date(0, 0, n+1, 0, 0), nil
+	case HourUnit:
+		return c.date(0, 0, 0, n, 0), c.date(0, 0, 0, n+1, 0), nil
+	case MinuteUnit:
+		return c.date(0, 0, 0, 0, n), c.date(0, 0, 0, 0, n+1), nil
+	}
+	buckets, err := ParseDurationUnit(unit)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return buckets.StartOf(num), buckets.StartOf(num + 1), nil
+}
+
+// date returns local time shifted from 1 Jan 1970 in the calendar location
+func (c *Calendar) date(years, months, days, hours, minutes int) time.Time {
+	return time.Date(1970+years, time.January+time.Month(months), 1+days, hours, minutes, 0, 0, c.location)
+}
+
+// MonthNumOf returns month number of t in the calendar location
+func (c *Calendar) MonthNumOf(t time.Time) UnitNum {
+	t = t.In(c.location)
+	return UnitNum(int(t.Month()) + (t.Year()-1970)*12)
+}
+
+// WeekNumOf returns week number of t, weeks start on the first day of week of the calendar
+func (c *Calendar) WeekNumOf(t time.Time) UnitNum {
+	// amount of days between the start of week and the zero unix time which was on Thursday
+	shift := (int64(time.Thursday) - int64(c.weekStart) + period.DaysInWeek) % period.DaysInWeek
+	return UnitNum((c.daysOf(t)+shift)/period.DaysInWeek) + 1
+}
+
+// DayNumOf returns day number of t, days start at local midnight
+func (c *Calendar) DayNumOf(t time.Time) UnitNum {
+	return UnitNum(c.daysOf(t)) + 1
+}
+
+// HourNumOf returns hour number of t counted in local time
+// during daylight saving time transitions an hour can be repeated or skipped
+func (c *Calendar) HourNumOf(t time.Time) UnitNum {
+	return UnitNum(c.daysOf(t)*24+int64(t.In(c.location).Hour())) + 1
+}
+
+// MinuteNumOf returns minute number of t counted in local time
+func (c *Calendar) MinuteNumOf(t time.Time) UnitNum {
+	local := t.In(c.location)
+	return UnitNum(c.daysOf(t)*24*60+int64(local.Hour()*60+local.Minute())) + 1
+}
+
+// daysOf returns amount of local days passed since 1 Jan 1970
+func (c *Calendar) daysOf(t time.Time) int64 {
+	y, m, d := t.In(c.location).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / int64(period.Day/time.Second)
+}
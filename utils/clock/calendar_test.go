@@ -0,0 +1,114 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	t.Run("UTC calendar should match global functions", func(t *testing.T) {
+		for _, dt := range []time.Time{
+			time.Date(1970, 1, 8, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 14, 23, 59, 59, 0, time.UTC),
+			time.Date(2018, 12, 31, 12, 30, 0, 0, time.UTC),
+		} {
+			if UTCCalendar.DayNumOf(dt) != DayNumOf(dt) {
+				t.Errorf("Day of %s should be %d", dt, DayNumOf(dt))
+			}
+			if UTCCalendar.WeekNumOf(dt) != WeekNumOf(dt) {
+				t.Errorf("Week of %s should be %d", dt, WeekNumOf(dt))
+			}
+			if UTCCalendar.MonthNumOf(dt) != MonthNumOf(dt) {
+				t.Errorf("Month of %s should be %d", dt, MonthNumOf(dt))
+			}
+			if UTCCalendar.HourNumOf(dt) != HourNumOf(dt) {
+				t.Errorf("Hour of %s should be %d", dt, HourNumOf(dt))
+			}
+			if UTCCalendar.MinuteNumOf(dt) != MinuteNumOf(dt) {
+				t.Errorf("Minute of %s should be %d", dt, MinuteNumOf(dt))
+			}
+		}
+	})
+
+	t.Run("days and months should start at local midnight", func(t *testing.T) {
+		cal := NewCalendar(time.FixedZone("UTC+3", 3*60*60), time.Monday)
+		// 1 Feb 00:30 in UTC+3
+		dt := time.Date(2018, 1, 31, 21, 30, 0, 0, time.UTC)
+		if n := cal.DayNumOf(dt); n != DayNumOf(dt)+1 {
+			t.Errorf("Day should be %d but was %d", DayNumOf(dt)+1, n)
+		}
+		if n := cal.MonthNumOf(dt); n != MonthNumOf(dt)+1 {
+			t.Errorf("Month should be %d but was %d", MonthNumOf(dt)+1, n)
+		}
+		if n := cal.HourNumOf(dt); n != HourNumOf(dt)+3 {
+			t.Errorf("Hour should be %d but was %d", HourNumOf(dt)+3, n)
+		}
+	})
+
+	t.Run("weeks should start on the configured day", func(t *testing.T) {
+		// 1 Jan 2018 was Monday
+		monday := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+		tests := []struct {
+			weekStart time.Weekday
+			sameWeek  time.Time
+			nextWeek  time.Time
+		}{
+			{time.Monday, monday.Add(6 * 24 * time.Hour), monday.Add(7 * 24 * time.Hour)},
+			{time.Sunday, monday.Add(5 * 24 * time.Hour), monday.Add(6 * 24 * time.Hour)},
+		}
+		for _, ts := range tests {
+			cal := NewCalendar(nil, ts.weekStart)
+			week := cal.WeekNumOf(monday)
+			if cal.WeekNumOf(ts.sameWeek) != week {
+				t.Errorf("%s should be in the same week for weeks starting on %s", ts.sameWeek, ts.weekStart)
+			}
+			if cal.WeekNumOf(ts.nextWeek) != week+1 {
+				t.Errorf("%s should be in the next week for weeks starting on %s", ts.nextWeek, ts.weekStart)
+			}
+		}
+	})
+
+	t.Run("NumOf should support duration units and reject unknown ones", func(t *testing.T) {
+		dt := time.Date(1970, 1, 1, 9, 0, 0, 0, time.UTC)
+		n, err := NewCalendar(time.FixedZone("UTC+3", 3*60*60), time.Monday).NumOf(DurationUnit(4*time.Hour), dt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 3 {
+			t.Errorf("Should be 3 but was %d", n)
+		}
+		if _, err := UTCCalendar.NumOfFunc(Unit("u")); err == nil {
+			t.Error("Should return error")
+		}
+	})
+
+	t.Run("RangeOf should return bounds of the unit", func(t *testing.T) {
+		zone := time.FixedZone("UTC+3", 3*60*60)
+		dt := time.Date(2018, 2, 14, 13, 45, 30, 0, zone)
+		units := []Unit{MonthUnit, WeekUnit, DayUnit, HourUnit, MinuteUnit, DurationUnit(4 * time.Hour)}
+		for _, cal := range []*Calendar{UTCCalendar, NewCalendar(zone, time.Monday), NewCalendar(zone, time.Sunday)} {
+			for _, unit := range units {
+				n, err := cal.NumOf(unit, dt)
+				if err != nil {
+					t.Fatal(err)
+				}
+				from, to, err := cal.RangeOf(unit, n)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if dt.Before(from) || !dt.Before(to) {
+					t.Errorf("%s should be within [%s, %s) of unit %s", dt, from, to, unit)
+				}
+				if m, _ := cal.NumOf(unit, from); m != n {
+					t.Errorf("start of unit %s should be in unit %d but was in %d", unit, n, m)
+				}
+				if m, _ := cal.NumOf(unit, to); m != n+1 {
+					t.Errorf("end of unit %s should be in unit %d but was in %d", unit, n+1, m)
+				}
+			}
+		}
+		if _, _, err := UTCCalendar.RangeOf(Unit("u"), 1); err == nil {
+			t.Error("Should return error")
+		}
+	})
+}
@@ -41,13 +41,21 @@ const (
 	WeekUnit = Unit("w")
 	// MonthUnit is a unit code for month
 	MonthUnit = Unit("m")
+	// HourUnit is a unit code for hour
+	HourUnit = Unit("h")
+	// MinuteUnit is a unit code for minute
+	MinuteUnit = Unit("min")
 )
 
+// ValidUnit returns error if unit is neither a known unit nor a duration unit
 func ValidUnit(unit Unit) error {
 	switch unit {
-	case DayUnit, WeekUnit, MonthUnit:
+	case DayUnit, WeekUnit, MonthUnit, HourUnit, MinuteUnit:
 		return nil
 	default:
+		if _, err := ParseDurationUnit(unit); err == nil {
+			return nil
+		}
 		return fmt.Errorf("Unit %s is unknown", unit)
 	}
 }
@@ -73,6 +81,16 @@ func DayNum() UnitNum {
 	return DayNumOf(Now())
 }
 
+// HourNum return current hour number
+func HourNum() UnitNum {
+	return HourNumOf(Now())
+}
+
+// MinuteNum return current minute number
+func MinuteNum() UnitNum {
+	return MinuteNumOf(Now())
+}
+
 // WeekNumOf returns week number of specified time t
 func WeekNumOf(t time.Time) UnitNum {
 	return (DayNumOf(t)-1)/period.DaysInWeek + 1
@@ -87,3 +105,22 @@ func MonthNumOf(t time.Time) UnitNum {
 func DayNumOf(t time.Time) UnitNum {
 	return UnitNum(t.UnixNano()/int64(period.Day)) + 1
 }
+
+// HourNumOf returns hour number of specified time t
+func HourNumOf(t time.Time) UnitNum {
+	return UnitNum(t.UnixNano()/int64(time.Hour)) + 1
+}
+
+// MinuteNumOf returns minute number of specified time t
+func MinuteNumOf(t time.Time) UnitNum {
+	return UnitNum(t.UnixNano()/int64(time.Minute)) + 1
+}
+
+// RangeOf returns time range [from, to) of the unit number as computed by the global functions for current time
+// months are in the local time zone, other units are counted from the zero unix time
+func RangeOf(unit Unit, num UnitNum) (from time.Time, to time.Time, err error) {
+	if unit == MonthUnit {
+		return NewCalendar(time.Local, time.Thursday).RangeOf(unit, num)
+	}
+	return UTCCalendar.RangeOf(unit, num)
+}
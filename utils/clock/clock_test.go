@@ -87,8 +87,35 @@ func TestClock(t *testing.T) {
 		}
 	})
 
+	t.Run("HourNumOf should return proper hour", func(t *testing.T) {
+		dt := time.Date(1970, 1, 2, 3, 30, 0, 0, time.UTC)
+		n := HourNumOf(dt)
+		if n != 28 {
+			t.Fatalf("Should be 28 but was %d", n)
+		}
+	})
+
+	t.Run("MinuteNumOf should return proper minute", func(t *testing.T) {
+		dt := time.Date(1970, 1, 1, 1, 2, 30, 0, time.UTC)
+		n := MinuteNumOf(dt)
+		if n != 63 {
+			t.Fatalf("Should be 63 but was %d", n)
+		}
+	})
+
+	t.Run("HourNum and MinuteNum should return proper units", func(t *testing.T) {
+		dt := time.Date(1970, 1, 1, 2, 5, 0, 0, time.UTC)
+		Static(dt)
+		if n := HourNum(); n != 3 {
+			t.Fatalf("Hour should be 3 but was %d", n)
+		}
+		if n := MinuteNum(); n != 126 {
+			t.Fatalf("Minute should be 126 but was %d", n)
+		}
+	})
+
 	t.Run("ValidUnit should return true if unit is known", func(t *testing.T) {
-		for _, u := range []Unit{DayUnit, WeekUnit, MonthUnit} {
+		for _, u := range []Unit{DayUnit, WeekUnit, MonthUnit, HourUnit, MinuteUnit} {
 			if ValidUnit(u) != nil {
 				t.Fatal("Should not return error")
 			}
@@ -0,0 +1,73 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a source of current time
+type Clock interface {
+	Now() time.Time
+}
+
+type globalClock struct{}
+
+func (globalClock) Now() time.Time {
+	return Now()
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Global is a clock returning the package time, it follows Custom, Static and Reset
+// it is the default clock of filters
+var Global Clock = globalClock{}
+
+// Real is a clock returning the real current time regardless of the package time
+var Real Clock = realClock{}
+
+// StaticClock is a clock which always returns the same time
+type StaticClock time.Time
+
+// Now returns the static time
+func (c StaticClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// ManualClock is a clock which time is changed only manually, it is safe for concurrent use
+type ManualClock struct {
+	lock sync.RWMutex
+	now  time.Time
+}
+
+// NewManualClock creates a manual clock starting at t
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{
+		now: t,
+	}
+}
+
+// Now returns current time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.now
+}
+
+// Set sets current time of the clock
+func (c *ManualClock) Set(t time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = t
+}
+
+// Advance moves current time of the clock by d
+func (c *ManualClock) Advance(d time.Duration) time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
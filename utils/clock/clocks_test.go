@@ -0,0 +1,43 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClocks(t *testing.T) {
+	t.Run("Global clock should follow package time", func(t *testing.T) {
+		dt := time.Date(1999, 10, 23, 11, 34, 5, 10, time.UTC)
+		Static(dt)
+		defer Reset()
+		if Global.Now() != dt {
+			t.Error("Should be exactly time")
+		}
+		if Real.Now().Equal(dt) {
+			t.Error("Real clock should not follow package time")
+		}
+	})
+
+	t.Run("StaticClock should return the same time", func(t *testing.T) {
+		dt := time.Date(1999, 10, 23, 11, 34, 5, 10, time.UTC)
+		var c Clock = StaticClock(dt)
+		if c.Now() != dt {
+			t.Error("Should be exactly time")
+		}
+	})
+
+	t.Run("ManualClock should change time only manually", func(t *testing.T) {
+		dt := time.Date(1999, 10, 23, 11, 34, 5, 10, time.UTC)
+		c := NewManualClock(dt)
+		if c.Now() != dt {
+			t.Fatal("Should be exactly time")
+		}
+		if c.Advance(time.Hour) != dt.Add(time.Hour) || c.Now() != dt.Add(time.Hour) {
+			t.Fatal("Should be advanced by an hour")
+		}
+		c.Set(dt)
+		if c.Now() != dt {
+			t.Fatal("Should be set back")
+		}
+	})
+}
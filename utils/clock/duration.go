@@ -0,0 +1,104 @@
+package clock
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/Applifier/go-bloomd/utils/period"
+)
+
+// durationUnitRegexp matches duration units like 4h or 3d_3600s where the second part is an offset of the origin
+var durationUnitRegexp = regexp.MustCompile(`^([1-9][0-9]*)(d|h|min|s)(?:_([1-9][0-9]*)s)?$`)
+
+var durationUnitSuffixes = []struct {
+	suffix   string
+	duration time.Duration
+}{
+	{"d", period.Day},
+	{"h", time.Hour},
+	{"min", time.Minute},
+	{"s", time.Second},
+}
+
+// DurationUnit returns a unit of buckets with duration d aligned to the zero unix time
+// d is expected to be a positive number of seconds, otherwise the unit is not valid
+func DurationUnit(d time.Duration) Unit {
+	return DurationUnitFrom(d, time.Unix(0, 0))
+}
+
+// DurationUnitFrom returns a unit of buckets with duration d aligned to the origin
+// units with origins which differ by a multiple of d are the same
+func DurationUnitFrom(d time.Duration, origin time.Time) Unit {
+	if d <= 0 || d%time.Second != 0 {
+		return Unit(d.String())
+	}
+	unit := ""
+	for _, s := range durationUnitSuffixes {
+		if d%s.duration == 0 {
+			unit = strconv.FormatInt(int64(d/s.duration), 10) + s.suffix
+			break
+		}
+	}
+	offset := time.Duration(origin.Unix()) * time.Second % d
+	if offset < 0 {
+		offset += d
+	}
+	if offset != 0 {
+		unit += "_" + strconv.FormatInt(int64(offset/time.Second), 10) + "s"
+	}
+	return Unit(unit)
+}
+
+// DurationBuckets splits time into buckets of the same duration
+type DurationBuckets struct {
+	// Duration is a duration of a single bucket
+	Duration time.Duration
+	// Offset is a start of the first bucket after the zero unix time
+	Offset time.Duration
+}
+
+// ParseDurationUnit returns buckets of a unit created by DurationUnit or DurationUnitFrom
+func ParseDurationUnit(unit Unit) (DurationBuckets, error) {
+	match := durationUnitRegexp.FindStringSubmatch(string(unit))
+	if match == nil {
+		return DurationBuckets{}, fmt.Errorf("Unit %s is not a duration unit", unit)
+	}
+	n, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return DurationBuckets{}, err
+	}
+	var b DurationBuckets
+	for _, s := range durationUnitSuffixes {
+		if s.suffix == match[2] {
+			b.Duration = time.Duration(n) * s.duration
+		}
+	}
+	if match[3] != "" {
+		offset, err := strconv.ParseInt(match[3], 10, 64)
+		if err != nil {
+			return DurationBuckets{}, err
+		}
+		b.Offset = time.Duration(offset) * time.Second
+		if b.Offset >= b.Duration {
+			return DurationBuckets{}, fmt.Errorf("Unit %s has offset greater than duration", unit)
+		}
+	}
+	return b, nil
+}
+
+// Num returns current bucket number
+func (b DurationBuckets) Num() UnitNum {
+	return b.NumOf(Now())
+}
+
+// NumOf returns number of the bucket containing t, the bucket starting at the offset is number 1
+func (b DurationBuckets) NumOf(t time.Time) UnitNum {
+	return UnitNum((t.UnixNano()-int64(b.Offset))/int64(b.Duration)) + 1
+}
+
+// StartOf returns start time of the bucket
+func (b DurationBuckets) StartOf(num UnitNum) time.Time {
+	return time.Unix(0, int64(num-1)*int64(b.Duration)+int64(b.Offset))
+}
@@ -0,0 +1,87 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationUnit(t *testing.T) {
+	t.Run("DurationUnit should use the largest whole suffix", func(t *testing.T) {
+		tests := map[time.Duration]Unit{
+			72 * time.Hour:   Unit("3d"),
+			4 * time.Hour:    Unit("4h"),
+			90 * time.Minute: Unit("90min"),
+			45 * time.Second: Unit("45s"),
+		}
+		for d, expected := range tests {
+			if unit := DurationUnit(d); unit != expected {
+				t.Errorf("Unit of %s expected to be %s but was %s", d, expected, unit)
+			}
+		}
+	})
+
+	t.Run("DurationUnitFrom should keep offset of the origin", func(t *testing.T) {
+		origin := time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC)
+		if unit := DurationUnitFrom(4*time.Hour, origin); unit != Unit("4h_3600s") {
+			t.Fatalf("Unit expected to be 4h_3600s but was %s", unit)
+		}
+		if unit := DurationUnitFrom(4*time.Hour, origin.Add(-8*time.Hour)); unit != Unit("4h_3600s") {
+			t.Fatalf("Unit expected to be 4h_3600s but was %s", unit)
+		}
+		if unit := DurationUnitFrom(time.Hour, origin); unit != Unit("1h") {
+			t.Fatalf("Unit expected to be 1h but was %s", unit)
+		}
+	})
+
+	t.Run("invalid durations should not produce valid units", func(t *testing.T) {
+		for _, d := range []time.Duration{0, -time.Hour, 1500 * time.Millisecond} {
+			if ValidUnit(DurationUnit(d)) == nil {
+				t.Errorf("Unit of %s should not be valid", d)
+			}
+		}
+	})
+
+	t.Run("ParseDurationUnit should parse valid units only", func(t *testing.T) {
+		b, err := ParseDurationUnit(Unit("4h_3600s"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Duration != 4*time.Hour || b.Offset != time.Hour {
+			t.Fatalf("Unexpected buckets %+v", b)
+		}
+		for _, u := range []Unit{"h", "0h", "4x", "4h_0s", "1h_3600s", "4h3600s"} {
+			if _, err := ParseDurationUnit(u); err == nil {
+				t.Errorf("Unit %s should not be parsed", u)
+			}
+		}
+	})
+
+	t.Run("NumOf should count buckets from the origin", func(t *testing.T) {
+		b, _ := ParseDurationUnit(DurationUnit(4 * time.Hour))
+		if n := b.NumOf(time.Date(1970, 1, 1, 3, 59, 0, 0, time.UTC)); n != 1 {
+			t.Fatalf("Should be 1 but was %d", n)
+		}
+		if n := b.NumOf(time.Date(1970, 1, 2, 4, 0, 0, 0, time.UTC)); n != 8 {
+			t.Fatalf("Should be 8 but was %d", n)
+		}
+
+		origin := time.Date(2018, 1, 1, 1, 0, 0, 0, time.UTC)
+		b, _ = ParseDurationUnit(DurationUnitFrom(4*time.Hour, origin))
+		n := b.NumOf(origin)
+		if b.NumOf(origin.Add(4*time.Hour-time.Nanosecond)) != n || b.NumOf(origin.Add(4*time.Hour)) != n+1 {
+			t.Fatal("Bucket should start at the origin")
+		}
+		if !b.StartOf(n).Equal(origin) {
+			t.Fatalf("Bucket should start at %s but starts at %s", origin, b.StartOf(n))
+		}
+	})
+
+	t.Run("Num should return current bucket", func(t *testing.T) {
+		Static(time.Date(1970, 1, 1, 9, 0, 0, 0, time.UTC))
+		defer Reset()
+		b, _ := ParseDurationUnit(DurationUnit(4 * time.Hour))
+		if n := b.Num(); n != 3 {
+			t.Fatalf("Should be 3 but was %d", n)
+		}
+	})
+}
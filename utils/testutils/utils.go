@@ -10,28 +10,40 @@ var iterSleepDefault = 10 * time.Millisecond
 var timeout = timeoutDefault
 var iterSleep = iterSleepDefault
 
-func Eventually(test func() error) (err error) {
+// Eventually calls test until it succeeds or timeout passes, it returns the last error of test
+func Eventually(test func() error) error {
 	done := make(chan struct{})
+	defer close(done)
+	results := make(chan error)
+	sleep := iterSleep
 	go func() {
 		for {
+			err := test()
 			select {
+			case results <- err:
 			case <-done:
-				break
-			default:
-				err = test()
-				if err == nil {
-					close(done)
-					break
-				}
-				<-time.After(iterSleep)
+				return
+			}
+			if err == nil {
+				return
+			}
+			select {
+			case <-time.After(sleep):
+			case <-done:
+				return
 			}
 		}
 	}()
-	select {
-	case <-done:
-		return err
-	case <-time.After(timeout):
-		close(done)
-		return err
+	var err error
+	expired := time.After(timeout)
+	for {
+		select {
+		case err = <-results:
+			if err == nil {
+				return nil
+			}
+		case <-expired:
+			return err
+		}
 	}
 }